@@ -0,0 +1,143 @@
+package urlshort
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Options configures the handler returned by SetHandler
+type Options struct {
+	// AdminToken must be sent as a bearer token, or as the password of
+	// HTTP basic authentication from a browser, to reach the /admin/
+	// endpoints. The endpoints are disabled while it is empty.
+	AdminToken string
+	// Disable lists features to switch off, see Features
+	Disable []string
+	// Enable lists features that are off unless switched on, see OptIn
+	Enable []string
+	// Reload, when set, is run by POST /admin/reload
+	Reload func() ReloadResult
+	// Codes configures the short codes generated by POST /shorten
+	Codes CodeOptions
+	// Prefix moves every built-in page below /<Prefix>/, /_/list for "_",
+	// leaving all other paths to links. Without it the pages keep their
+	// top level paths, which links cannot take.
+	Prefix string
+	// BasePath is the path a reverse proxy serves the server under, such as
+	// /go. Requests may arrive with or without it; pages and redirects
+	// always include it.
+	BasePath string
+}
+
+// Features that Options.Disable can switch off, and those in OptIn that
+// Options.Enable switches on
+const (
+	FeatureList    = "list"    // the /list page
+	FeatureStats   = "stats"   // the /stats/ analytics endpoint
+	FeatureImport  = "import"  // importing from the web UI
+	FeatureExport  = "export"  // exporting from the web UI
+	FeatureAdmin   = "admin"   // every /admin/ endpoint
+	FeatureCreate  = "create"  // creating a missing link from the not found page
+	FeatureAPI     = "api"     // the /api/v1/ JSON API
+	FeatureShorten = "shorten" // generating short codes with POST /shorten
+	FeatureEdit    = "edit"    // adding, editing and deleting links on the /list page with the admin token
+)
+
+// Features lists the features that can be disabled
+var Features = []string{FeatureList, FeatureStats, FeatureImport, FeatureExport, FeatureAdmin, FeatureCreate, FeatureAPI, FeatureShorten, FeatureEdit}
+
+// OptIn lists the features that are off by default. Anyone may generate
+// codes with shorten, so it is only served when asked for.
+var OptIn = []string{FeatureShorten}
+
+// enabled reports whether feature is switched on
+func (o Options) enabled(feature string) bool {
+	if contains(o.Disable, feature) {
+		return false
+	}
+	return !contains(OptIn, feature) || contains(o.Enable, feature)
+}
+
+// requireToken only passes requests carrying the admin token to h
+func requireToken(token string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if status, msg := checkToken(token, w, r); status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// checkToken returns the status and message to answer r with when it does
+// not carry the admin token, or 0. Challenges are added to w. Browsers send
+// basic auth along with posts other sites make, so those must also carry
+// the form token.
+func checkToken(token string, w http.ResponseWriter, r *http.Request) (int, string) {
+	if token == "" {
+		return http.StatusForbidden, "admin endpoints are disabled"
+	}
+	var given string
+	basic := false
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	} else if _, pass, ok := r.BasicAuth(); ok {
+		given, basic = pass, true
+	}
+	if given == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		w.Header().Add("WWW-Authenticate", `Bearer realm="map"`)
+		w.Header().Add("WWW-Authenticate", `Basic realm="map"`)
+		return http.StatusUnauthorized, "unauthorized"
+	}
+	if basic && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if err := checkCSRF(r); err != nil {
+			return http.StatusForbidden, err.Error()
+		}
+	}
+	return 0, ""
+}
+
+// backupHandler streams a backup of the store
+func backupHandler(table *Table) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := table.Flush(); err != nil {
+			log.Printf("Backing up without some visit counts: %v", err)
+		}
+		name := fmt.Sprintf("map-%v.bak", time.Now().Format("20060102T150405"))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		if err := table.Store().Backup(w); err != nil {
+			// Headers are gone by now, the truncated body is all we can signal
+			log.Printf("Backup failed: %v", err)
+		}
+	}
+}
+
+// restoreHandler replaces the store with the backup in the request body
+// and reloads the table from it
+func restoreHandler(table *Table) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := table.Restore(r.Body); err != nil {
+			log.Printf("Restore failed: %v", err)
+			http.Error(w, "restore failed: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct{ Links int }{len(table.snapshot())})
+	}
+}
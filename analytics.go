@@ -0,0 +1,85 @@
+package urlshort
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Hourly buckets older than hourlyRetention are rolled up into daily buckets
+const hourlyRetention = 48 * time.Hour
+
+// Coarse user agent classes recorded with every visit
+var agentClasses = []struct {
+	class   string
+	markers []string
+}{
+	{"bot", []string{"bot", "crawler", "spider", "slurp", "preview"}},
+	{"cli", []string{"curl", "wget", "httpie", "go-http-client", "python-requests", "python-urllib"}},
+	{"mobile", []string{"mobi", "android", "iphone", "ipad"}},
+	{"browser", []string{"mozilla", "opera"}},
+}
+
+// agentClass reduces a User-Agent header to one of a few classes
+func agentClass(ua string) string {
+	ua = strings.ToLower(ua)
+	if ua == "" {
+		return "none"
+	}
+	for _, c := range agentClasses {
+		for _, m := range c.markers {
+			if strings.Contains(ua, m) {
+				return c.class
+			}
+		}
+	}
+	return "other"
+}
+
+// referrerHost returns only the host of the Referer header
+func referrerHost(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// visitFrom describes the redirect of r through the link at path
+func visitFrom(r *http.Request, path string) persist.Visit {
+	return persist.Visit{
+		Path:     path,
+		Time:     time.Now(),
+		Referrer: referrerHost(r.Referer()),
+		Agent:    agentClass(r.UserAgent()),
+		Count:    1,
+	}
+}
+
+// statsHandler serves the visit history of the link named by the rest of
+// the path as JSON
+func statsHandler(table *Table) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		urlpath := strings.TrimPrefix(r.URL.Path, "/stats/")
+		if _, ok := table.Lookup(urlpath); !ok {
+			http.NotFound(w, r)
+			return
+		}
+		s, err := table.Store().Stats(urlpath)
+		if err != nil {
+			log.Printf("Failed to read stats for %v: %v", urlpath, err)
+			http.Error(w, "failed to read stats", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	}
+}
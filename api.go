@@ -0,0 +1,240 @@
+package urlshort
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"urlshort/assets"
+	"urlshort/persist"
+)
+
+// APILinks is the collection of links in the JSON API. A link is addressed
+// by its path below it, /api/v1/links/team/wiki for team/wiki.
+const APILinks = "/api/v1/links"
+
+// APIDoc is where the OpenAPI description of the API is served
+const APIDoc = "/api/v1/openapi.json"
+
+// maxAPIBody bounds the link sent in a request
+const maxAPIBody = 64 << 10
+
+// APIError is the body of every API response with a 4xx or 5xx status
+type APIError struct {
+	Status  int    `json:"status"`
+	Message string `json:"error"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func apiErrorf(status int, format string, args ...interface{}) *APIError {
+	return &APIError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// etag identifies the version of s served by the API. Visit counts change
+// on their own and are left out.
+func etag(s persist.Short) string {
+	r := toRecord(s)
+	r.Visits, r.LastVisited = 0, time.Time{}
+	r.Created, r.Updated = r.Created.UTC(), r.Updated.UTC()
+	b, _ := json.Marshal(r)
+	sum := sha1.Sum(b)
+	return `"` + hex.EncodeToString(sum[:10]) + `"`
+}
+
+// matchETag reports whether the If-Match or If-None-Match header value h
+// names tag, * naming any existing link
+func matchETag(h, tag string, exists bool) bool {
+	for _, t := range strings.Split(h, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" && exists || exists && strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPreconditions applies the If-Match and If-None-Match headers of r to
+// the link currently stored, if any
+func checkPreconditions(r *http.Request, old persist.Short, exists bool) error {
+	var tag string
+	if exists {
+		tag = etag(old)
+	}
+	if h := r.Header.Get("If-Match"); h != "" && !matchETag(h, tag, exists) {
+		return apiErrorf(http.StatusPreconditionFailed, "the link has changed or does not exist, fetch it again")
+	}
+	if h := r.Header.Get("If-None-Match"); h != "" && matchETag(h, tag, exists) {
+		return apiErrorf(http.StatusPreconditionFailed, "/%v already exists", old.Path)
+	}
+	return nil
+}
+
+// apiHandler serves the link API. Reading is open; creating, updating and
+// deleting need the admin token.
+//
+//	GET    /api/v1/links          every link, ?tag= to filter
+//	POST   /api/v1/links          create a link
+//	GET    /api/v1/links/{path}   one link, with its ETag
+//	PUT    /api/v1/links/{path}   create or replace a link, If-Match to guard
+//	DELETE /api/v1/links/{path}   delete a link, If-Match to guard
+func apiHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := strings.Trim(strings.TrimPrefix(r.URL.Path, APILinks), "/")
+		allow := "GET, PUT, DELETE"
+		if p == "" {
+			allow = "GET, POST"
+		}
+		if !contains(strings.Split(allow, ", "), r.Method) {
+			w.Header().Set("Allow", allow)
+			apiRespond(w, http.StatusMethodNotAllowed, apiErrorf(http.StatusMethodNotAllowed, "%v is not allowed here", r.Method))
+			return
+		}
+		if r.Method != http.MethodGet {
+			if status, msg := checkToken(opts.AdminToken, w, r); status != 0 {
+				apiRespond(w, status, apiErrorf(status, "%v", msg))
+				return
+			}
+		}
+
+		var err error
+		switch {
+		case p == "" && r.Method == http.MethodGet:
+			err = apiList(w, r, table)
+		case p == "":
+			err = apiPut(w, r, table, opts, "")
+		case r.Method == http.MethodGet:
+			s, ok := table.Lookup(p)
+			if !ok {
+				err = apiErrorf(http.StatusNotFound, "/%v does not exist", p)
+				break
+			}
+			w.Header().Set("ETag", etag(s))
+			apiRespond(w, http.StatusOK, toRecord(s))
+		case r.Method == http.MethodPut:
+			err = apiPut(w, r, table, opts, p)
+		case r.Method == http.MethodDelete:
+			err = table.DeleteIf(p, func(old persist.Short) error {
+				return checkPreconditions(r, old, true)
+			})
+			if errors.Is(err, persist.ErrNotFound) {
+				err = apiErrorf(http.StatusNotFound, "/%v does not exist", p)
+			} else if err == nil {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}
+		if err != nil {
+			var e *APIError
+			if !errors.As(err, &e) {
+				e = apiErrorf(http.StatusInternalServerError, "%v", err)
+			}
+			apiRespond(w, e.Status, e)
+		}
+	}
+}
+
+func apiList(w http.ResponseWriter, r *http.Request, table *Table) error {
+	tag := r.URL.Query().Get("tag")
+	recs := []record{}
+	for _, s := range table.All() {
+		if tag == "" || contains(s.Tags, tag) {
+			recs = append(recs, toRecord(s))
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Path < recs[j].Path })
+	apiRespond(w, http.StatusOK, recs)
+	return nil
+}
+
+// apiPut saves the link in the request body under p, or under the path it
+// names when p is empty, in which case the link must be new
+func apiPut(w http.ResponseWriter, r *http.Request, table *Table, opts Options, p string) error {
+	var rec record
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rec); err != nil {
+		return apiErrorf(http.StatusBadRequest, "cannot read the link: %v", err)
+	}
+	// Counts and times are kept by the server
+	s := rec.short()
+	s.Count, s.Created, s.Updated, s.LastVisited = 0, time.Time{}, time.Time{}, time.Time{}
+	create := p == ""
+	switch {
+	case create:
+		p = s.Path
+	case s.Path == "":
+		s.Path = p
+	case s.Path != p:
+		return apiErrorf(http.StatusBadRequest, "the link names /%v but was sent to /%v", s.Path, p)
+	}
+	if err := checkLink(s); err != nil {
+		return apiErrorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+	if err := checkAbsolute(s.Site); err != nil {
+		return apiErrorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+	if err := table.checkReserved(s.Path); err != nil {
+		return apiErrorf(http.StatusUnprocessableEntity, "%v", err)
+	}
+	var existed bool
+	err := table.SaveIf(s, func(old persist.Short, exists bool) error {
+		existed = exists
+		if create && exists {
+			return apiErrorf(http.StatusConflict, "/%v already exists", p)
+		}
+		return checkPreconditions(r, old, exists)
+	})
+	if errors.Is(err, ErrGenerated) {
+		return apiErrorf(http.StatusConflict, "%v", err)
+	}
+	if err != nil {
+		return err
+	}
+	saved, _ := table.Lookup(p)
+	w.Header().Set("ETag", etag(saved))
+	status := http.StatusOK
+	if !existed {
+		w.Header().Set("Location", opts.page(APILinks[1:]+"/"+(&url.URL{Path: p}).EscapedPath()))
+		status = http.StatusCreated
+	}
+	apiRespond(w, status, toRecord(saved))
+	return nil
+}
+
+// apiDocHandler serves the OpenAPI description of the API, with a server
+// entry naming the prefix and base path when the pages have one
+func apiDocHandler(opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, err := assets.Asset("static/openapi.json")
+		if err != nil {
+			apiRespond(w, http.StatusInternalServerError, apiErrorf(http.StatusInternalServerError, "%v", err))
+			return
+		}
+		if root := strings.TrimSuffix(opts.page(""), "/"); root != "" {
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(b, &doc); err != nil {
+				apiRespond(w, http.StatusInternalServerError, apiErrorf(http.StatusInternalServerError, "%v", err))
+				return
+			}
+			doc["servers"], _ = json.Marshal([]map[string]string{{"url": root}})
+			b, _ = json.MarshalIndent(doc, "", "  ")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}
+}
+
+func apiRespond(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
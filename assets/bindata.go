@@ -25,7 +25,7 @@ func bindata_read(data []byte, name string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-var _static_map_js = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x55\xc1\x6e\xdb\x46\x10\xbd\xf3\x2b\x1e\x74\x48\x29\x9b\x91\xec\xab\x1d\x05\xa8\xed\xa0\x0d\xe0\xb8\x40\xe3\xf6\x62\xf8\xb0\x22\x47\xe2\x42\xd4\xae\xba\x3b\x8c\x44\x34\xfe\xf7\x62\x76\x49\x8a\x4c\xa3\xb4\xb9\x25\x07\x43\xe1\xce\x9b\x7d\x6f\x66\xde\xec\xaa\x36\x39\x6b\x6b\xb0\xd2\x15\x93\xfb\xdd\xee\x7d\x3a\xc5\xdf\x09\x30\x9f\xe3\x8e\xf2\x4a\x39\xc2\x27\xe5\xb4\x5a\x56\xe4\x13\xc8\x6f\x68\xb3\xab\x39\x6b\x21\x19\x58\xce\x32\xb0\xfc\x2c\x32\xe8\x0c\x7c\xe0\x3f\x55\x55\xd3\x75\x82\x18\x8c\x05\x0a\x9b\xd7\x5b\x32\x3c\x5b\x13\xbf\xab\x48\x7e\xde\x34\xef\x8b\x74\xe2\x49\xb9\xbc\x7c\xa4\x03\x4f\xa6\x02\x88\x69\xb1\x88\xc8\xd9\x27\x49\x34\x63\xfb\xc7\x6e\x47\xee\x56\x79\x4a\x43\x54\xb8\xf4\x9b\x69\x1f\x25\x22\xa6\x64\x49\x17\x10\x83\x30\x7f\xd3\x3c\xaa\xf5\x83\xda\x52\x3a\x61\x27\x81\x51\xf5\xbd\xb5\x3b\x70\xe9\x6c\xbd\x2e\xa1\xaa\xaa\xbd\xca\xd9\xbd\xcf\xa0\x4c\x81\x52\x17\x04\x2e\xad\x27\xec\x4b\x8b\xc2\x9a\x9f\x18\x5b\xc5\x79\x09\x2e\x09\x51\x0f\xfe\xaa\xc9\x35\x22\xc7\x3a\xa4\x1a\x0b\x5c\x5c\x43\xe3\x0d\xd8\xcd\x2a\x32\x6b\x2e\xaf\xa1\xcf\xcf\x63\xad\x01\x2e\x84\xa1\x7b\xd2\xcf\xa7\x18\x16\x93\xe9\xd3\xc5\xf3\x75\x88\xd6\x2b\xa4\x5c\x74\x58\xf4\xf5\x96\x1c\xc5\x8c\xe9\xc0\xb7\xd6\x30\x19\xc6\xe7\xcf\xf2\x45\x1b\x43\x4e\x2a\x7c\xdd\x02\x42\x82\x16\x34\xae\xed\x4c\x9b\x82\x0e\xbf\xad\xd2\xd8\x86\x29\xde\xe2\xf5\xe5\xf1\x26\xb4\x24\x3d\x37\x15\xcd\x0a\xed\x77\x95\x6a\xb0\xc0\x64\xd2\xa5\x7e\x01\x55\x9e\xfe\x1b\x60\xac\xa1\x23\x28\xe9\xfe\xbe\x24\x2f\x49\xd2\x4f\xa5\xb7\x8e\x43\x1f\x53\x13\x39\xc8\xf8\xb5\xf3\x16\x1b\xe2\xf7\x9a\xf3\x52\x9b\x75\x18\xbc\x43\x86\x26\x83\x2f\x6d\x5d\x15\x1f\xc3\x49\x86\x42\xbb\x2e\x2c\xb7\xb5\xe1\xd0\x8a\xef\x9d\xa0\xfe\x9a\xd0\xa6\x38\xd9\xf3\x39\x3e\x12\xc7\x9e\x5b\xc7\x72\x58\x68\x47\x91\x39\x5b\x28\x9f\x93\x29\xb4\x59\x5f\x25\x90\x13\x51\xad\x7c\x1e\x44\xcf\xcf\xf0\x41\x6d\x08\x0a\x55\x1c\x37\xc5\xd8\xeb\xaa\x42\x6e\x0d\x6b\x53\x13\x6a\xc3\xba\x4a\x00\x63\x07\x97\x97\xca\x63\x49\x64\x64\xe8\xe8\x0a\x67\xf3\x04\xd8\x97\xba\x22\xa4\x7d\x50\xd7\x2c\xa1\xc7\xca\x31\x96\x0d\xbc\x6a\x84\xc7\x38\x99\xf6\x31\x4d\x88\x1e\x0a\x5c\xa9\xca\x53\x6c\x8d\x14\xb9\xf7\x8e\xfc\x27\x7e\x9e\x9f\x7d\xcb\x26\x48\xe9\x90\xd3\x2e\x94\x26\x84\xaf\xb4\xf3\x9c\x09\xd3\xbc\x0c\x0a\x95\x36\xbe\x05\x94\xa4\x0a\x72\x7e\xda\xaa\x19\x18\xe6\x32\x1a\x26\x95\x94\xad\x67\xf0\x1a\x97\xd3\x91\x71\xbe\xa6\x53\xee\x75\xd4\x8e\x01\x96\x34\x92\x7d\xd5\xc2\x86\x43\x32\xd6\x1c\xe4\xfd\xd2\x76\x96\xf7\x16\xd4\xda\x11\x8d\xad\xb1\x57\x86\xa5\xbb\xb9\xdd\xee\x94\xa3\xac\x85\x58\x43\x58\x39\xbb\x45\x5e\x3b\x27\xce\x73\x76\x1f\xb6\x45\x7f\x20\xd9\x0c\x1d\xb8\x17\x0a\x1c\xb0\x08\x05\x3b\x6d\xfb\xc7\xbb\xc9\xf4\xc9\x3c\x77\xc4\x9a\x1e\x80\x73\x5c\xfe\x4f\xd0\xfc\x0c\xb7\x25\xe5\x1b\x71\x7d\x27\x29\xb4\xa9\x2d\x50\x2c\x0d\x76\x95\xca\x7b\x39\x4b\xe5\x49\xb8\x07\x40\x3f\xd5\x99\xcc\x34\xac\x43\x41\x3e\x1f\xe8\x90\x75\x12\x06\xbc\x9d\xf0\xe1\xbe\x90\xb3\x43\xdc\x40\xbf\x3e\x7e\xb8\x9f\xb1\xbd\xb7\xfb\x6e\xdb\xe0\x2d\x9a\x53\x67\xc3\x24\xa1\xcb\xef\x57\xf0\x36\xc3\x56\xb9\x0d\x94\x87\xea\x88\x4b\x95\x97\x8e\xd4\x26\x90\x15\x3f\x5d\x0d\x80\x5f\xf4\xb9\x33\x6f\xf7\x2f\x00\x8f\x1f\x5e\xc6\x5b\x6c\x28\x4c\x34\x7f\x87\xb2\x37\x3f\xaa\xb2\xe4\xf8\x57\x14\x0c\xb3\x0c\x4c\x75\x26\xa4\x7a\x22\xfd\xde\x11\x8a\x54\x08\xd5\x0d\xc5\xcd\x17\x02\x5a\x94\xf0\x0d\x22\xc2\x3e\xfb\x37\x7a\xb0\xb5\xba\xe5\x22\xa3\x2f\x3e\x32\xfc\x60\x0b\x9a\x69\xe3\xc9\xf1\x0d\xad\xac\xa3\x74\x30\xe9\x59\x17\x3c\xed\x14\x7d\x6d\x23\xb7\xe5\x7c\xa7\xe4\x29\xd6\x5b\x3a\x52\x68\x77\x5d\x06\x6d\x72\x47\xca\x0b\x79\xed\x11\x1f\x84\x65\x83\xcb\xab\x51\xda\xf0\xfd\xfc\x3c\xe6\xfc\xe2\x45\x8b\xa5\x39\xbd\x96\xf1\xf3\xc3\xdd\xd8\x36\x72\x7b\xf0\x45\xe7\x2e\xdf\x2e\x97\xd1\x73\x11\x07\x2c\xd4\xd0\xd5\xd1\x78\x71\xb5\x87\x17\x42\xad\x95\x36\xb3\xb1\xe5\x46\x8f\xda\x02\x17\x78\xf5\x0a\xa7\x6c\xd8\xbe\x3f\xe1\x8e\xe3\x50\x9c\x2a\xe2\xf8\x3d\xfe\x27\x00\x00\xff\xff\x05\x9b\x7e\xa4\x25\x0a\x00\x00")
+var _static_map_js = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcc\x55\xc1\x6e\xdb\x46\x10\xbd\xf3\x2b\x1e\x74\x48\x29\x9b\x91\xec\xab\x15\x05\xa8\xed\xa0\x0d\xe0\xb8\x40\xe3\xf6\x62\xf8\xb0\x22\x47\xe2\xc2\xd4\xae\xba\x3b\x8c\x44\x34\xfe\xf7\x62\x76\x49\x8a\x4c\xa3\xb4\xb9\xc5\x07\x81\xe6\xce\x9b\x7d\x6f\xe6\xcd\x70\x5d\x9b\x9c\xb5\x35\x58\xeb\x8a\xc9\xfd\x6e\xf7\x3e\x9d\xe2\xef\x04\x98\xcf\x71\x4b\x79\xa5\x1c\xe1\x93\x72\x5a\xad\x2a\xf2\x09\xe4\x19\xda\xec\x6a\xce\x5a\x48\x06\x96\xb3\x0c\x2c\x8f\x45\x06\x9d\x81\x0f\xfc\xa7\xaa\x6a\x5a\x24\x88\xc1\x58\xa2\xb0\x79\xbd\x25\xc3\xb3\x0d\xf1\xbb\x8a\xe4\xf1\xba\x79\x5f\xa4\x13\x4f\xca\xe5\xe5\x03\x1d\x78\x32\x15\x40\x4c\x8b\x65\x44\xce\x3e\x49\xa2\x19\xdb\x3f\x76\x3b\x72\x37\xca\x53\x1a\xa2\xc2\xa5\xdf\x4c\xfb\x20\x11\x31\x25\x4b\xba\x80\x18\x84\xf9\xeb\xe6\x41\x6d\xee\xd5\x96\xd2\x09\x3b\x09\x8c\xaa\xef\xac\xdd\x81\x4b\x67\xeb\x4d\x09\x55\x55\xed\x55\xce\xee\x7d\x06\x65\x0a\x94\xba\x20\x70\x69\x3d\x61\x5f\x5a\x14\xd6\xfc\xc4\xd8\x2a\xce\x4b\x70\x49\x88\x7a\xf0\x57\x4d\xae\x11\x39\xd6\x21\xd5\x58\xe2\x62\x01\x8d\x37\x60\x37\xab\xc8\x6c\xb8\x5c\x40\x9f\x9f\xc7\x5a\x03\x5c\x08\x43\xf7\xa8\x9f\x4e\x31\x2c\x26\xd3\xc7\x8b\xa7\x45\x88\xd6\x6b\xa4\x5c\x74\x58\xf4\xf5\x96\x1c\xc5\x8c\xe9\xc0\x37\xd6\x30\x19\xc6\xe7\xcf\xf2\x46\x1b\x43\x4e\x2a\xbc\x68\x01\x21\x41\x0b\x1a\xd7\x76\xa6\x4d\x41\x87\xdf\xd6\x69\x6c\xc3\x14\x6f\xf1\xfa\xf2\x78\x13\x5a\x92\x9e\x9b\x8a\x66\x85\xf6\xbb\x4a\x35\x58\x62\x32\xe9\x52\xbf\x80\x2a\x4f\xff\x0d\x30\xd6\xd0\x11\x94\x74\xbf\x2f\xc9\x4b\x92\xf4\xae\xf4\xd6\x71\xe8\x63\x6a\x22\x07\xb1\x5f\xeb\xb7\xd8\x10\xbf\xd7\x9c\x97\xda\x6c\x82\xf1\x0e\x19\x9a\x0c\xbe\xb4\x75\x55\x7c\x0c\x27\x19\x0a\xed\xba\xb0\xdc\xd6\x86\x43\x2b\xbe\xd7\x41\xfd\x35\xa1\x4d\xd1\xd9\xf3\x39\x3e\x12\xc7\x9e\x5b\xc7\x72\x58\x68\x47\x91\x39\x5b\x28\x9f\x93\x29\xb4\xd9\x5c\x25\x90\x13\x51\xad\x7c\x1e\x44\xcf\xcf\xf0\x41\x3d\x13\x14\xaa\x68\x37\xc5\xd8\xeb\xaa\x42\x6e\x0d\x6b\x53\x13\x6a\xc3\xba\x4a\x00\x63\x07\x97\x97\xca\x63\x45\x64\xc4\x74\x74\x85\xb3\x79\x02\xec\x4b\x5d\x11\xd2\x3e\xa8\x6b\x96\xd0\x63\xe5\x18\xab\x06\x5e\x35\xc2\x63\x9c\x4c\xfb\x98\x26\x44\x0f\x05\xae\x55\xe5\x29\xb6\x46\x8a\xdc\xcf\x8e\xfc\x13\x5f\xcf\xcf\xbe\x35\x26\x48\xe9\x90\xd3\x2e\x94\x26\x84\xaf\xb5\xf3\x9c\x09\xd3\xbc\x0c\x0a\x95\x36\xbe\x05\x94\xa4\x0a\x72\x7e\xda\xaa\x19\x0c\xcc\x65\x1c\x98\x54\x52\xb6\x33\x83\xd7\xb8\x9c\x8e\x06\xe7\x6b\x3a\xe5\x5e\x47\xad\x0d\xb0\xa2\x91\xec\xab\x16\x36\x34\xc9\x58\x73\x90\xf7\x4b\xdb\x59\xde\x5b\x50\x3b\x8e\x68\x6c\x8d\xbd\x32\x0c\xb6\xc8\xed\x76\xa7\x1c\x65\x2d\xc4\x1a\xc2\xda\xd9\x2d\xf2\xda\x39\x32\x2c\x85\x08\xdb\xa2\x3f\x90\x6c\x86\x0e\xdc\x0b\x05\x0e\x58\x86\x82\x9d\x1e\xfb\x87\xdb\xc9\xf4\xd1\x3c\x75\xc4\x9a\x1e\x80\x73\x5c\xfe\x4f\xd0\xfc\x0c\x37\x25\xe5\xcf\xd0\xeb\x5e\x92\x24\xe9\x0a\x14\x4b\x83\x5d\xa5\xf2\x5e\xce\x4a\x79\x12\xee\x01\xd0\xbb\x3a\x13\x4f\xc3\x3a\x14\xe4\xf3\x81\x0e\x59\x27\xc1\xe0\xad\xc3\x87\xfb\x42\xce\x0e\x71\x03\xfd\xfa\xf0\xe1\x6e\xc6\xf6\xce\xee\xbb\x6d\x83\xb7\x68\x4e\x9d\x0d\x93\x84\x2e\xbf\x5f\xc3\xdb\x0c\x5b\xe5\x9e\xa1\x3c\x54\x47\x5c\xaa\xbc\x72\xa4\x9e\x03\x59\x99\xa7\xab\x01\xf0\x8b\x3e\x77\xc3\xdb\xfd\x05\xe0\xf1\xc5\xcb\x78\x8b\x0d\x85\x15\xf4\x5d\xca\xde\xfc\xa8\xca\x92\xe3\xaf\x28\x18\x66\x19\x0c\xd5\x99\x90\xea\x89\xf4\x7b\x47\x28\x52\x21\x54\x9f\x29\x6e\xbe\x10\xd0\xa2\x84\xaf\x44\xc4\x7d\xf6\x6f\xf4\x60\x6b\x75\xcb\x45\xac\x2f\x73\x64\xf8\xde\x16\x34\xd3\xc6\x93\xe3\x6b\x5a\x5b\x47\xe9\xc0\xe9\x59\x17\x3c\xed\x14\x7d\x6d\x23\xb7\xe5\x7c\xa7\xf2\x12\xac\xb7\x74\xa4\xd0\xee\xba\x0c\xda\xe4\x8e\x94\x17\xf2\xda\x23\x7e\x10\x56\x0d\x2e\xaf\x46\x69\xc3\xfb\xf3\xf3\x45\x32\xf0\xc2\xb8\x34\xa7\xd7\x32\x7e\xbe\xbf\x1d\x8f\x8d\xdc\x1e\xe6\xa2\x9b\x2e\x4f\xfc\x45\x04\xdb\xd6\x60\xa1\x86\xae\x8e\x83\x17\x57\xbb\xf4\x1d\x6a\xa3\xb4\x99\x8d\x47\x6e\xf4\x51\x5b\xe2\x02\xaf\x5e\xe1\xd4\x18\xb6\xdf\x9f\x70\xc7\xd1\x14\xa7\x8a\x38\xfe\x1e\xff\x33\x00\x05\x9b\x7e\xa4\x25\x0a\x00\x00")
 
 func static_map_js() ([]byte, error) {
 	return bindata_read(
@@ -34,7 +34,16 @@ func static_map_js() ([]byte, error) {
 	)
 }
 
-var _static_searchicon_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xea\x0c\xf0\x73\xe7\xe5\x92\xe2\x62\x60\x60\xe0\xf5\xf4\x70\x09\x62\x60\x60\x10\x05\x61\x0e\x36\x06\x06\x86\x95\xe2\x4b\xa7\x31\x30\x30\x30\x16\x07\xb9\x3b\x31\xac\x3b\x27\xf3\x92\x81\x81\x81\x25\xdd\xd1\xd7\x91\x81\x61\x63\x3f\xf7\x9f\x44\x56\x06\x06\x06\xce\x02\x8f\xc8\x62\x06\x06\xa1\x12\x10\x66\xbc\x97\x26\x5f\xc1\xc0\xc0\xc0\x94\xe4\xed\xee\xc2\xf0\xbf\xbd\xff\xcc\x7e\x90\x92\xb2\x02\xc7\x74\x06\x06\x46\x2d\x06\x06\x46\x4d\x86\x00\xb1\x54\x43\x06\x06\x06\xd5\x12\xd7\x88\x92\x94\xc4\x92\x54\xab\xe4\xa2\xd4\xc4\x92\x54\x06\x23\x03\x43\x63\x5d\x03\x13\x5d\x43\x83\x10\x03\x33\x2b\x53\x4b\x2b\x03\x73\x5d\x03\x73\x2b\x03\x83\x3e\xc7\xce\x40\x14\x0d\xb9\xf9\x29\x99\x69\x95\xb8\x35\xfc\x97\x31\x7c\xcb\xc0\xc0\x20\x09\xd2\x10\x9c\x9f\x56\x52\x9e\x58\x94\xca\x50\x5e\x5e\xae\x97\x99\x97\x5d\x9c\x9c\x58\x90\xaa\x97\x5f\x94\x3e\xfb\x9d\x8d\x14\x03\x03\x83\x20\x48\x51\x48\x66\x49\x4e\x2a\x43\x71\x6a\x62\x51\x72\x86\x6e\x66\x72\x7e\xde\xa1\xe6\x37\xb5\x0c\x0c\x4c\x5a\x9e\x2e\x8e\x21\x16\xfe\x4b\xa7\x78\xae\x7e\xe5\x20\xd0\xaa\xfd\x76\x8d\x42\xdf\x03\x2f\xc1\x70\xbd\xa9\x3a\x2c\x82\x47\xeb\xdf\x7a\x15\xb0\xb0\x1e\x5c\xba\x68\x51\xb4\xfa\x1c\x81\xe7\xbf\xdf\x69\x5e\x52\xbc\xb8\xe2\xc3\x3b\x76\x45\xcf\xf2\xaf\x2b\x5f\xad\x7a\x15\x56\x57\xa7\xc8\xfc\xfd\xf7\xdc\x65\x97\x35\xd7\x86\x69\x5b\xc9\xf5\xdb\x3c\x2b\xdb\xad\x29\xc1\x13\xa4\x34\xf1\xc1\xfb\xfc\x19\x2e\xec\xcd\x8c\xbd\xc7\x93\x8b\xac\xe6\xaa\xb4\x28\xb8\x06\x56\xc8\xa8\xf5\x1f\x6f\x5e\x77\x5d\xfb\x68\x92\xe1\xa9\x14\x23\x9b\x7f\x7c\xb1\x5b\x2e\xbf\xbc\x90\xb6\x59\xb1\xfd\xf0\x41\xe1\x0e\x09\x27\x86\x47\x65\xbb\xe7\xee\x37\xe4\x9b\x9d\xb4\xf1\xc4\xff\xb0\x0d\x1a\x4a\x73\xce\xa6\xc9\x2c\x14\xf1\x5e\x1a\x24\xc2\xd7\x6e\x99\x64\x21\xdd\xeb\x66\xc3\xfe\xf1\xf6\x5b\xb7\x46\x87\x86\x90\xd0\xab\x29\x67\x34\xf6\xb3\xd9\x4f\xf5\x6a\xb5\x91\xe3\xef\x56\x4c\xff\x59\x13\x37\xdb\xec\xf2\xeb\xaf\x19\x7b\xe3\x36\x7f\x78\x59\xb2\x28\x46\xef\xf8\xa3\xf3\x1c\x22\xac\x82\x4c\x1d\xfb\x84\x59\x6e\xe6\x4c\xcb\x4d\x6c\x17\x51\xf8\x1f\xc6\x11\x22\x19\x7d\xe4\xda\x86\xa7\xf9\x4b\x2e\x67\x6e\x3b\x3a\xeb\x69\xd1\xca\xde\x59\x82\x19\xd2\x22\x4c\xf7\x94\x27\x76\x98\xfd\x99\x17\xa0\x71\x64\x5b\x84\xf2\xee\x47\x92\x0b\x45\xe7\x4f\xd4\xfd\xc2\xe9\x92\x71\x24\x28\xc4\xcc\x98\x65\xfb\x6d\xdd\xfa\x7a\x26\x33\x23\x2f\x85\xe8\xb3\x1d\x07\x3c\x1b\xcd\x67\x9f\x3d\x96\xfb\xb9\xf7\x61\xc1\x09\x27\x81\xad\xab\x6c\x04\x3e\x56\xcc\xd1\x9b\xce\x9d\xcf\xfd\xf7\xfa\xfb\xef\x5b\x57\x86\x3f\x10\xb1\xd5\xe6\x9e\xed\xa3\xb6\x55\xd2\xf8\xd0\xeb\xaf\x41\x26\x52\xa9\x8e\x2e\x06\xf3\xcf\xaf\xbc\x99\x53\x64\xef\x55\xb6\x5b\xb6\x74\x77\xec\x2c\x1f\xb5\x3b\x41\x1d\x42\xfc\xd7\x22\x17\xca\x5f\xce\xda\xba\x6a\xbe\xc4\x12\x66\xbf\x14\x41\x01\xc9\x4b\xcd\xec\xcf\x8c\x96\xdf\x48\xdc\x19\x73\xad\x7f\xdb\x91\x92\x85\x5b\x6e\xe7\xea\xb1\x2c\xaa\x7c\xc7\x32\xe3\xa0\xe6\x3d\x81\x83\x36\x73\x1e\x4d\xd2\x9c\xea\xf5\xa4\x68\xa7\xc9\x69\xf5\xc0\x0d\x11\x3a\x3f\x96\x28\x94\x07\x49\x74\xff\xad\xb3\x97\xe7\x2b\xe1\x9e\xf3\x58\x57\xd9\x6e\xfe\xf9\x9a\x83\x0f\x56\x34\x8b\x07\x2a\x1b\xb0\x1d\xd3\x60\x28\xbb\x9c\xb5\x35\xae\x85\xbb\x95\xe9\x67\xcd\xbe\xf3\x8b\xe6\xdf\x78\xff\xfd\x4a\x86\x89\xcf\xa1\x1d\x29\x02\x1f\xeb\xbb\x9b\x0e\x3f\x74\xf8\x5a\x15\x2b\xee\x36\x2b\xe0\x85\x7d\x27\xd3\x1d\xd9\xad\x51\xbe\xfb\x5f\x5e\xd0\xac\x17\x63\x3d\xa1\xfe\x4b\x62\xee\x82\x55\x5a\x7f\x99\xb9\x97\xa7\x6e\xa9\xeb\xda\x7c\x8e\x81\x81\x81\xc1\xd3\xd5\xcf\x65\x9d\x53\x42\x13\x20\x00\x00\xff\xff\x2b\x20\x37\xe6\x5c\x03\x00\x00")
+var _static_openapi_json = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xe4\x58\x4b\x8f\xdb\x36\x10\xbe\xef\xaf\x18\xb0\x3d\x05\xca\x7a\xbd\xd9\x4b\x53\xf4\xd0\x47\x50\x04\xd8\x36\xdb\xac\xd3\x1e\x82\x45\x30\x2b\x8e\x24\x26\x12\xa9\x90\x23\x6f\x0d\x43\xff\xbd\x20\x25\xcb\x92\x2d\x3f\xe2\x6c\xdb\xa0\xf5\xc9\x22\x67\x86\xf3\xf8\xf8\x71\xc8\xe5\x19\x80\x30\x25\x69\x2c\x95\x78\x0e\xe2\xd9\xf9\xc5\xf9\x33\x11\xf9\x51\xa5\x13\x23\x9e\x83\x97\x00\x10\xac\x38\x27\x2f\x51\x60\x09\xb9\xd2\x1f\xe0\xfb\x9b\x97\x41\x10\x40\xcc\xc9\x3a\x65\xb4\x9f\x9e\xae\xc6\x24\xb9\xd8\xaa\x92\xdb\xf1\x1f\x2d\x21\x53\x04\x96\x50\x46\x50\x95\x12\x99\x00\xb5\x04\x49\x39\x31\x01\x67\x04\x2e\x33\x96\xe3\x8a\x1d\x38\xb2\x73\x92\x70\xbf\x80\x02\xcb\x73\x78\x4d\x28\x95\x4e\x41\x39\xf0\xbe\x7e\x0b\x71\x86\x3a\x25\x07\x9a\x48\x06\x55\x94\x85\xd2\xc0\xe6\x03\x69\x40\x07\x08\xf7\x84\x96\x6c\x33\x72\x2e\xce\x00\xea\x10\x54\x89\x9c\xb9\x75\x54\x13\x2c\xd5\x64\x3e\x9d\xf8\x80\xd6\xc3\x00\x22\x25\xee\x7d\x02\x08\x57\x15\x05\xda\x85\x78\x0e\xe2\x5a\x39\x06\x9a\x93\x5d\x84\x44\x88\x68\x2d\x65\x4a\xb2\xe8\x43\x7e\x29\xbd\x64\xae\x1c\x5f\x07\xd3\x3d\x99\x12\x2d\x16\xc4\x64\xfd\x82\x6f\xbb\x71\x80\xa5\xd0\x58\x84\x1c\x33\xa6\x22\xf2\x15\xf0\x1f\x1f\x2b\xb2\x0b\xff\x69\xe9\x63\xa5\x2c\x79\xcb\x09\xe6\x8e\xa2\xad\x24\xbf\xd2\x79\xe3\x93\x83\x18\xad\x5d\xf8\x9c\x71\xa6\x1c\xb4\x06\x5d\x9c\x51\x81\x3e\x30\xc1\x8b\x32\x2c\xe5\xd8\x2a\x9d\x8a\xba\xee\x1c\xb9\xeb\xf9\x6a\xc9\x95\x46\x3b\x72\x83\x64\x00\x88\xcb\x8b\x8b\x60\x66\xc3\x81\x59\x46\xcd\xfa\x11\x38\x63\xb9\x29\xa1\xcf\xb9\x5f\x3d\x36\x9a\x49\x87\xbc\x0a\x2c\xcb\x5c\xc5\x21\x55\x93\xf7\x2e\x28\x2f\xc7\xdc\x43\x6b\x31\x04\xaf\x98\x8a\xe0\x85\xf8\xda\x52\xe2\xa7\xbe\x9a\xc4\xa6\x28\x8d\x26\xcd\x6e\xd2\xa8\xba\x89\xcf\xb6\xa8\xfd\xaf\xf3\x76\xf5\xaf\x5e\xc5\x25\x4a\xe3\x76\x57\xb7\x01\x2a\xe0\xa1\xda\xc6\x41\xee\x7a\x43\xc8\x51\x5c\x59\xc5\xde\xd2\xdb\xa5\x08\xe8\xf3\x7f\xef\xea\x61\x56\x3f\x56\xe4\xf8\x07\x23\x17\x21\xa2\x5e\x61\xd9\x56\xf4\x89\xa9\x3a\x2e\x1f\xc7\x15\x75\xba\xdb\x62\xa7\x35\xb9\xc5\x39\x49\xd1\xb3\x08\x20\xae\x2e\x2e\x8e\x51\x7d\x61\xad\xb1\x5b\xaa\xd3\xd3\x55\x9f\x9d\xae\xfa\xcd\xc9\xaa\x97\x97\x9f\xa0\xba\x8d\xc3\xb3\x1e\x1a\x87\x0c\x34\x59\xfa\xbd\x52\xf7\x89\x68\x07\x5b\xac\xb9\x62\xb5\xbb\x94\xee\x7f\x6d\x43\x6a\x63\xa7\xde\x20\x67\x60\x92\x40\x9e\x7e\xed\x08\x1e\x32\x15\x67\x50\xe0\x02\x32\x93\x4b\x70\x39\xba\x8c\xdc\x91\xb4\x71\x17\x1d\xc3\x9c\x3f\x13\x1f\xde\x58\x29\xf1\xe6\xae\x3a\x48\x43\x27\x21\xf6\xea\xb3\xaa\xb8\x66\x93\xea\x20\x99\x18\x0b\x96\xca\x1c\xe3\x23\x78\xa5\xac\xf8\x24\x52\x39\xe2\x58\x79\x99\x3c\xfd\x05\x39\xee\xc1\x25\x23\x94\x64\x8f\x3b\x5c\x5e\xcc\x30\xed\xe0\x02\x45\xe5\x18\x1c\xab\x3c\x87\x0c\xe7\x74\x08\x26\xd1\x2e\x87\x7e\x35\x9a\x3e\xc7\xab\x27\xc0\x06\x8c\x3f\xf7\x1a\x42\xee\x3c\x3c\xf1\xbc\xfb\x52\x99\xf9\x64\x9c\xff\xaf\x48\x7d\x7a\xf9\x6f\x91\xfa\xca\x98\x68\xfa\xd9\x9d\x8c\xf0\x53\x98\x3e\x4c\x03\x8d\x99\xff\x20\x13\x7c\x6a\x9f\x79\x35\xd6\x67\x36\x59\x94\x5f\x48\x27\x71\xf5\xcf\xe0\x75\x57\x27\xd1\x5d\x6e\xd6\xca\x5d\x1a\x3b\xc4\xdc\xfa\x92\x0c\xf2\xdb\xc1\x67\x5d\xa4\x8c\xb9\xec\xea\x17\x46\x9a\x6b\x94\x88\x60\xac\xd3\xef\x5f\xba\xda\x56\x22\x5c\xdc\x56\xbe\xb6\xc1\x8e\x56\x57\x34\x84\x33\xd8\x26\x3b\x2e\x13\xfd\x0d\xd0\xc0\x34\x18\x0a\x30\x1c\x03\xc7\xef\xcd\x6d\x74\xd8\xdd\x24\xc6\x42\x1f\xf5\xfb\x20\xda\xa7\xe4\x47\xe7\xfb\x2d\xc2\x68\xaa\xbb\x2f\x11\x7f\x64\x8b\x10\x49\x7b\x3a\x41\x82\x2a\x27\x29\xfe\x0e\x2f\x5b\xa8\xd5\xe3\xcd\x6a\x2b\xd5\x2f\x63\x08\x6b\xe0\xfc\x2a\x9d\xe6\xfe\x3d\xc5\x2c\x36\x4e\xd7\x96\x59\xde\x8a\xca\xe6\xa2\x4f\x01\x28\xa5\xf2\x3e\x63\x7e\x63\x3d\x17\xb2\x22\xd7\x51\xd0\x5a\xac\xec\x4f\x0e\xa8\x22\xb4\xbe\x23\xf5\xdc\xc6\xee\x6d\xfb\xce\x00\x0f\x8a\x33\x53\x71\x03\x93\xf6\x8d\x21\xf4\xbd\xe7\x30\x43\x8f\xea\xc4\x9a\x22\xcc\xbe\x79\x7d\x0d\x0f\x19\x69\xc8\x29\x61\xf0\x3a\x26\x81\x9b\x37\xb3\xf3\x8d\x1d\xed\xa3\x3a\xca\x87\x19\xda\x94\x78\xab\xeb\x5e\x4e\xeb\x08\x96\x4f\xea\xf0\x34\xb2\xf4\x74\x5d\x43\x68\x1c\xfd\xac\x07\xfe\x70\xb9\xa1\xd1\x6d\x28\x0f\x84\x19\x53\x77\xe8\x72\xbd\xb7\x71\x6b\xee\xbc\xc6\x8e\x4a\x0e\x04\xe7\xca\x29\x1e\x5a\x54\x9a\x29\x5d\x9d\x30\x28\xfd\x53\x45\xdb\x55\x8d\xac\x41\x72\xa0\xbb\xce\x62\x62\x6c\x81\xec\xc7\xfc\x03\xd2\x53\x56\x05\x1d\xb4\xd8\x3c\x36\x3d\xa6\xc5\x1c\x1d\xbf\x0b\x31\x3e\xaa\x59\x8f\xe0\x77\x85\x91\xb4\xc3\x26\xe9\xaa\x08\x7b\xc7\x7f\x60\x59\x92\x96\xfe\x9f\xb4\x26\xb0\x36\xfd\x89\x31\x8b\xbb\xa1\xcd\xf0\x7a\x74\xb4\xd1\x82\x6c\xda\xfa\x19\x50\xd7\x99\xdf\xb0\x9a\x58\x4c\x8b\x15\xe1\xec\x45\x42\x68\x46\xdc\x71\x7b\xe2\x37\xef\x2b\xac\xdb\x17\x40\x29\x49\x02\x9b\xf6\xb5\xcd\x92\x54\x96\x62\x8e\xc0\x55\x71\x06\xe8\xa0\xe2\xe2\x9d\x33\x95\x8d\xe9\xbb\x02\xcb\x8d\xa5\x53\xd2\xbe\xa5\xda\x28\xd1\xbd\x31\x39\xa1\x1e\xa3\x05\x62\x30\xba\x7d\x3f\x7b\xc8\x8c\xa3\xf0\x74\x05\xca\x01\x36\x6f\x93\x10\x1b\x49\x50\xa0\x24\xff\xae\x75\xf3\xea\x76\x06\x93\x30\x41\x7a\x6f\x4f\x38\x42\xf1\xbb\x59\x72\x37\xc3\x39\x46\xae\xc6\x77\xd5\x30\x72\x5a\xad\xb7\x55\x9b\x7d\x3d\xc4\x59\x7d\xf6\xd7\x00\x9a\x6e\xcf\x16\x0f\x16\x00\x00")
+
+func static_openapi_json() ([]byte, error) {
+	return bindata_read(
+		_static_openapi_json,
+		"static/openapi.json",
+	)
+}
+
+var _static_searchicon_png = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x74\xcb\x6f\x50\x93\x05\x1c\xc0\xf1\xdf\xc6\xf8\xa3\x4b\xdc\x74\xca\x86\x28\x9b\x14\x30\x70\xbb\x67\x40\x43\x77\x2b\x18\x37\xc0\x4d\x24\x78\x5a\x88\x21\x5c\x0f\xdb\x33\x36\x03\xb7\x7b\x78\xda\xb0\x23\x96\x4e\x8e\x11\x87\x3b\xba\x30\x8f\x8d\x16\x3b\x48\x47\xc5\xe1\xc1\x28\x6f\x97\x34\x04\xe1\x4a\xc4\x5c\xa1\x1e\x1e\x87\x94\x16\x18\x20\x57\x1c\x99\xd0\xba\xbd\xec\x45\x2f\xbe\xef\xbe\x9f\xe6\xa2\xc2\xfc\x6d\x5b\x63\xb7\x02\xc0\x36\xc5\x61\x39\x0a\x00\xbb\x42\x45\x45\x00\x80\x27\xa6\xe7\x02\x00\x50\x6a\xd1\xfc\x1c\xe8\xbb\x15\xb7\x00\x00\xb4\x2a\xd9\x51\x19\xc0\x15\x3b\x7d\x03\x0b\x07\x80\x2d\xc6\xc3\xc7\x6b\x01\x76\x90\xa1\x28\xb3\xda\xf8\x3a\x00\xa0\x56\x1e\xc9\x97\x43\xb0\xc9\x7e\x73\x38\xb4\x98\x8c\xb2\x2a\x00\x4a\x0a\x00\x85\x0f\x45\xbb\x71\x11\x00\xbc\x44\xe6\x96\x92\x1a\x8c\xc4\x25\x6a\x02\xc7\x48\x1c\xd2\x10\x51\xba\x00\xc9\x10\x88\x10\x15\x22\x96\xbc\x7c\x48\x82\x64\x0a\x90\x4c\x09\x82\x9c\x97\x35\x17\xff\x07\xd4\x18\x34\x7a\xed\xe9\xff\x07\xc1\x38\xd1\x12\x00\x70\x42\xe0\x75\x83\x96\x34\x63\x04\x0e\x66\xb3\x59\xa8\x3f\xf5\x76\xad\x1a\x33\xe2\x42\x03\x51\xe5\x58\x96\xc6\x02\x00\x33\x34\xa9\xf4\x64\x35\x0e\xb5\x38\x46\xa8\x75\x02\xbd\xda\x70\x6a\xc4\xfa\xfb\x7b\x00\xd4\x14\x85\x5c\xa6\x3a\xf8\x5a\xcf\x47\x8a\xcf\x17\xb3\x19\x8d\xa9\x4b\x5f\x70\xcf\xcf\x29\x99\xc7\x84\xed\x07\x68\xcc\x31\xcb\x92\xd2\x48\x0b\xf7\xf7\xb8\xdd\x65\x49\x4e\xc6\xaf\xcf\x97\xf9\x77\x78\x3f\x5c\x7e\xba\x1c\xc9\x53\x98\xd7\x3c\x8b\xbd\x8b\x25\x0d\x0d\xbc\xb0\xf5\xe7\x9d\x9f\x05\xf8\x5f\x96\xa4\x4a\xf6\xd9\xa5\x8f\x4d\x3e\x3e\xfb\x05\x74\x7f\xdb\xdc\x8a\xe1\xa2\x3c\xd2\x4a\x69\x1d\x57\x13\x92\xce\x17\xcf\x71\x73\x8b\xeb\xe2\x12\xed\xe3\xd6\xbe\xbb\xa9\x63\x95\xa2\xef\x34\x69\xd2\x7f\xa2\xcb\x07\x03\x0b\xb7\xb5\x03\xbc\xa6\xeb\xfe\x9d\x36\x76\x0e\xcc\x9b\x7c\x9d\xc3\xa2\x68\x47\xe5\x95\x89\x60\x49\x7f\xf2\x7e\xe7\xa4\x36\xae\x8b\x75\xa4\x07\x65\x45\x37\x1d\xaa\x3c\xb8\xa7\x35\x4f\x1a\xb9\x3a\xb3\x94\x77\x26\xfb\x7d\xd5\x1b\x3f\x69\x6e\x26\x0f\x47\x64\xb5\x2b\x1b\xa5\xfb\xb6\xb7\xf0\xaa\x9e\xd5\x57\x38\xc4\x81\x27\x6b\xba\x6f\x2a\x06\x9e\x2e\x90\xee\x13\xc2\xf1\xf9\xa9\x28\x56\x38\x93\x6a\xbb\xb6\x93\x76\xbf\xfa\x42\x0d\xd6\xc4\xe2\x06\x4b\xa2\x54\x9c\xb2\xd1\xe9\xfe\x47\x86\xee\x80\x7e\x68\xac\xe3\x11\xe1\x69\xed\x60\xea\xf6\xb0\xa8\xb3\x09\x6d\x36\xf1\xc6\x27\x45\xc9\xa3\x43\xa5\x09\xbe\x79\x4e\xd7\x2e\x57\x9b\xe0\xcf\x2d\x72\xdd\x28\xaa\x12\xa7\xd3\xbe\x9a\x11\x58\x2c\x54\x71\x9a\x92\x5b\x36\x69\xfb\x56\x71\x26\xd3\x31\x79\xa3\xe6\x8f\xd6\x87\xc6\x89\x1c\x86\xb7\x57\xca\x58\xad\x73\x0a\x3f\xa6\x1b\xe8\x9b\x77\x57\xd6\xbd\x9e\x63\x73\xac\x57\x52\xe9\x8e\x82\x44\x2f\x27\x7d\xe4\xc9\x1a\x9a\x11\x8b\xcb\xe4\x88\x6b\xca\x73\xbf\x9a\xc8\x52\x9a\x7c\x7b\xdf\xf1\x95\x77\x14\x24\x3e\x40\x6d\x3b\xb6\x4f\x1f\xef\x8a\x0f\x9c\xf4\xf6\xba\xd8\xdd\x61\x85\x1a\x26\x83\x73\xc7\x1a\xf9\x38\xed\xd2\x3d\xec\xea\x89\x69\xfb\xd0\x28\xd9\x35\x38\x53\x23\xa4\xb9\x4f\x2f\xd3\x2e\xfa\xf9\xb3\x0c\xbf\xd4\x39\xff\x21\xbf\x5d\xf9\x0b\x71\x35\xe3\xfb\xa4\xe2\xfe\xd2\x03\x7f\x75\x73\xcd\x28\xbb\x65\xb3\x21\x2b\x3e\x9a\xa4\x3b\x7f\x16\x24\xbc\xea\x9a\xaa\xf7\xcf\x5d\xb6\xc6\x14\x27\x20\x11\x37\x92\xc1\x14\x38\xe9\xad\x38\x47\x6f\xa4\x3e\xab\xbf\x36\xe5\x76\xdd\x5b\x59\xff\x51\x97\x51\x30\xf2\xb5\x86\xb1\x6a\x69\x39\x7b\xfd\x61\xf6\xda\xbb\xe5\x31\x79\x1d\x45\xbf\x65\x35\x53\x1f\xec\xf5\xbe\x79\x74\x78\xe1\x36\xdf\xb2\x3b\x7c\x22\xe9\x6f\x76\xe7\xa7\xbd\x29\x9b\x61\xf4\x4b\xf8\x60\xc3\x07\x03\xb7\x00\x00\x14\xb9\x85\xf2\xbe\x9c\xb7\xce\xfe\x3b\x00\x2b\x20\x37\xe6\x5c\x03\x00\x00")
 
 func static_searchicon_png() ([]byte, error) {
 	return bindata_read(
@@ -43,7 +52,7 @@ func static_searchicon_png() ([]byte, error) {
 	)
 }
 
-var _static_style_css = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xac\x54\xcd\x6e\xe2\x30\x10\xbe\xe7\x29\x46\x42\x55\x7f\x84\x21\xa1\x40\x77\xcd\xa9\xda\xb6\xfb\x02\xd5\xde\x9d\x78\x92\x58\x75\x6c\xcb\x31\x90\x76\xb5\xef\xbe\xb2\x5d\x20\x04\xa8\xb4\xd2\x3a\x17\x67\x3c\xdf\x8c\xc7\xdf\x7c\xe3\x58\x2e\x71\x92\xcb\x35\xbe\xfa\x1d\xfc\x4e\x00\x4a\xad\x1c\x29\x59\x23\xe4\x3b\x85\x5f\x68\x39\x53\x6c\x0c\x3f\x51\xe1\x86\x8d\xa1\x65\xaa\x25\x2d\x5a\x51\xae\x12\x80\x5c\x5b\x8e\x96\x42\x66\x3a\x68\xb5\x14\x1c\x46\xd9\x8f\xe5\xf3\xe3\xdc\x1f\x36\xcc\x56\x42\x11\x2b\xaa\xda\x51\x98\xa7\xa6\xf3\xd6\xe9\x1d\xe4\xac\x78\xab\xac\x5e\x2b\x4e\x0a\x2d\xb5\xa5\x30\x7a\x0e\x6b\x05\x77\xd3\x04\x60\x2b\xb8\xab\x29\x3c\x2c\xae\x3c\xc0\x61\xe7\x08\x93\xa2\x52\x14\x24\x96\xee\x90\xd7\xa3\x25\x33\x2d\x52\xd8\xed\x56\xc9\x9f\x24\x19\x56\xe5\xf8\xf8\xd4\x56\x87\x62\x0d\xe3\x5c\xa8\x8a\xc2\xbd\xe9\x60\xe6\x6f\x78\x0e\x1f\x7d\xcf\x14\xfb\x18\xd6\x85\xa4\x3d\x10\xc9\xb5\x73\xba\x39\xc2\x72\xce\xcf\x03\x73\xcd\xdf\x77\xf0\x40\x46\x2b\x3e\x90\x42\xb6\xbc\x74\x3d\x4b\x95\x76\x37\x13\x57\x23\xe3\xb7\xb4\xd6\x1b\xb4\x31\xf7\xe9\x3b\x97\x99\xff\x2e\x54\x89\x8c\x0f\x70\xb4\xcf\x67\xdf\x4c\x1a\xfd\x41\xa4\x50\xc8\x2c\xa9\x2c\xe3\x02\x95\xbb\x71\xda\x8c\x61\xb4\x58\x7c\x9f\xe5\x39\xa4\x57\x63\x18\xdd\xcf\x1e\x0a\xc6\x61\xb9\xf4\x3f\x31\x12\x64\x69\x7a\x75\x7b\x12\x6f\x8b\xf9\x9b\x70\xff\x33\xe4\x69\x28\x88\x2c\xfc\x53\xc0\x63\xfa\x66\x07\xfa\xe6\x61\x7d\xf1\x92\x9f\x5d\xd3\x67\xf0\x5b\x94\x40\x30\x6d\x31\xea\x22\xd7\x92\x7b\xe3\x8e\xa1\x97\xb0\x7a\xb9\x7d\xcf\x1f\x65\x7e\x4a\x9f\xe7\x2f\x8b\x2f\x33\xd3\x52\xd8\xd6\x91\xa2\x16\xf2\xa8\x0d\x63\x2c\xa5\x55\x14\xca\xa4\x61\x42\x3d\x89\x4d\x70\xb9\xac\xba\x02\x95\x43\x1b\x11\x6a\xdd\x04\xef\xfe\x79\x50\x78\x38\x16\xca\xac\xdd\xa4\x45\x66\x8b\xfa\x15\x3b\x37\x6c\x43\xd1\xb0\x0a\x29\xac\xad\xbc\xb9\x9e\xb6\x8e\x39\x51\x4c\xa3\xb7\x28\xb4\x9a\x18\x55\x5d\x0f\x78\x24\x46\xb7\xc2\x09\xad\x28\x64\xa9\xe9\x20\x9b\xc5\x37\xec\x79\x58\x34\xc8\x42\x59\x9f\xdb\xd5\xa1\x1a\x4f\xe4\xea\x9c\x8e\xf6\xd2\x27\x4e\x1b\xba\x0f\xbb\x33\xee\xf5\x3a\xb0\xc7\x07\xdc\xcd\xb2\x33\x13\x21\xa8\x7a\x3f\xfb\x86\x61\x72\xdd\xf9\x5b\x84\x89\xb3\x6f\xad\xa0\xea\xbf\x01\x00\x00\xff\xff\xc7\x0d\x3d\x8e\x8a\x05\x00\x00")
+var _static_style_css = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xac\x55\xdd\x6e\xe2\x3c\x10\xbd\xe7\x29\x46\x42\xa8\x3f\x22\x34\xfc\xf6\xfb\x8c\xf6\xa2\xdd\xd2\x7d\x81\x6a\x6f\x56\x7b\xe1\xc4\x93\xc4\x6a\x62\x5b\x8e\x69\x43\xab\x7d\xf7\x95\x6d\x0c\x81\x00\x52\xa5\x75\x6e\xa2\xb1\xcf\x8c\x67\xe6\x9c\xb1\xa1\x49\x89\xa3\xa4\x5c\xe3\x8b\xfd\x83\xcf\x1e\x40\x26\x85\x89\x32\x5a\xf1\x72\x43\xe0\x27\x6a\x46\x05\x1d\xc2\x0f\x14\xf8\x46\x87\x50\x53\x51\x47\x35\x6a\x9e\x2d\x7b\x00\x89\xd4\x0c\x35\x81\xb1\x6a\xa0\x96\x25\x67\xd0\x1f\x7f\x5f\xac\x1e\x66\x76\xb3\xa2\x3a\xe7\x22\xd2\x3c\x2f\x0c\x81\x59\xac\x1a\x6b\xbd\xbb\x85\x84\xa6\xaf\xb9\x96\x6b\xc1\xa2\x54\x96\x52\x13\xe8\xaf\xdc\x5a\xc2\xed\x5d\x0f\xe0\x9d\x33\x53\x10\xb8\x9f\x0f\x2c\xc0\x60\x63\x22\x5a\xf2\x5c\x10\x28\x31\x33\xfb\xb8\x16\x5d\x52\x55\x23\x81\xf0\xb7\xec\xfd\xe9\xf5\x8e\xb3\x32\x6c\xd8\xb5\x15\x2e\x59\x45\x19\xe3\x22\x27\x30\x55\x0d\x4c\x54\x73\x1a\xef\xcf\x9e\x48\xf6\xc1\xad\x33\x41\x5b\xa0\x28\x91\xc6\xc8\xea\x00\xcb\x18\x3b\x0d\x4c\x24\xdb\x04\xb8\x6b\x46\xcd\x3f\x90\xc0\x78\x71\xee\x7a\x9a\x08\x69\xae\x47\xa6\x40\xca\x6e\x48\x21\xdf\x50\xfb\xd8\xdd\x3a\x67\x63\xfb\x9d\xc9\x12\x29\x3b\xc2\x91\x76\x3f\xdb\xe6\xa8\x92\x1f\x51\xc9\x05\x52\x1d\xe5\x9a\x32\x8e\xc2\x5c\x1b\xa9\x86\xd0\x9f\xcf\xff\x9f\x24\x09\xc4\x83\x21\xf4\xa7\x93\xfb\x94\x32\x58\x2c\x06\xc3\xe0\x09\xc6\x71\x3c\xb8\xe9\xf8\x7b\xc7\xe4\x95\x9b\x7f\xe9\xb2\xeb\x0a\x7c\x17\xbe\xe4\xf0\xb0\x7d\x93\x7d\xfb\x66\x6e\x5d\xa8\xa4\x29\x3a\x1d\xfc\xcf\x4b\xc0\x99\xde\xd1\xeb\x22\x91\x25\xb3\xc6\xd0\xa1\x67\xb7\x5a\xb1\x2d\xe7\x0f\x22\x3f\xc5\xab\xd9\xf3\xfc\x62\x64\x92\x71\x5d\x9b\x28\x2d\x78\x79\x40\x43\xef\x4b\x48\xe1\x85\x32\xaa\x28\x17\x4f\xfc\x0d\x3e\x2f\xaa\x2e\x45\x61\x50\x7b\x84\x58\x57\xf0\x79\xb4\xef\x14\xee\xb7\x8d\xa6\xa2\xce\x50\x77\xce\x04\xe5\x6e\x87\xc2\x4e\x0f\x41\x73\x7b\x64\x26\xb5\x0f\xc1\x78\xad\x4a\xba\x21\xc0\x85\xed\x65\x0b\xbd\x2d\x49\x1c\xb0\xa8\xb5\xf4\x21\x43\x15\x1f\xe3\x38\x9e\xc4\xdb\x2b\x4b\xc3\x53\x3c\xd8\x1e\x3f\xce\x57\x61\x9b\x32\xf6\x2c\x75\x35\x84\x51\x2a\x45\xc6\x75\xf5\xf5\xbb\xb7\x81\x27\xa6\x44\xb8\x4c\x6b\xdc\xc4\x0e\xdd\x72\x41\x53\xc3\xa5\xa8\x2f\x64\xef\x12\x65\xdc\x70\x91\x03\x17\x6a\x6d\x7e\x99\x8d\xc2\x6f\xf6\xa2\xbf\xdb\x0d\xb4\xdc\xf5\xf4\x69\x2c\xef\x5c\xb8\x1d\x8d\x43\xb1\x69\x7e\x2a\x48\x94\x94\x32\x7d\x5d\x9e\x1e\x1d\x81\x76\x3b\x32\x59\x61\xad\x6b\x37\x3a\x8f\x72\x9b\xa9\xa6\x55\xaf\xed\x03\x30\x6d\x91\x7f\xab\x87\xe9\x36\x7b\x97\xce\xa8\x46\xaa\xd3\xe2\x05\x1b\x73\x3c\xbd\x78\x45\x73\x24\xb0\xd6\xe5\xf5\x95\x3f\xc5\x53\x29\x46\x4a\xe4\x57\x47\xb2\x8f\x94\xac\xb9\xad\xa4\xad\xc3\xae\xc0\x07\x27\x34\x2a\xa4\x4e\x05\xdb\xdf\x65\xb7\x76\x9d\xb1\xbb\x4b\x2f\x32\x52\x91\x9d\xdb\x60\x3c\xa4\xc4\xde\xee\x89\x1a\x9e\xbe\x13\xd4\x70\x8f\xc0\x19\x66\x5d\x6a\xe1\xdf\x01\x00\xb1\xa1\xef\xe1\xb9\x07\x00\x00")
 
 func static_style_css() ([]byte, error) {
 	return bindata_read(
@@ -52,7 +61,25 @@ func static_style_css() ([]byte, error) {
 	)
 }
 
-var _templates_list_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x54\x3d\x6f\xdb\x3c\x10\xde\xf3\x2b\xf8\x72\x8a\x81\x58\x7c\xdd\xa9\x03\xa9\x25\x69\xa7\x02\x0d\x92\xb4\x40\x47\x9a\x3c\x87\x57\x53\xa4\x40\x9e\xec\x1a\x82\xfe\x7b\x21\xc9\xb2\xa5\xa4\x43\xb4\xe8\xee\x9e\xfb\x7c\xee\x40\xf9\xdf\xc3\xf7\xfb\x97\x5f\x8f\x5f\x98\xa3\xca\x97\x37\xb2\xff\x31\xaf\xc3\xab\xe2\x10\x78\x79\xc3\x18\x63\xd2\x81\xb6\xa3\x38\xa8\x15\x90\x66\xc6\xe9\x94\x81\x14\x6f\x68\xb7\xfe\xcc\xdf\xc2\x41\x57\xa0\xf8\x01\xe1\x58\xc7\x44\x9c\x99\x18\x08\x02\x29\x7e\x44\x4b\x4e\x59\x38\xa0\x81\xf5\xa0\xdc\x31\x0c\x48\xa8\xfd\x3a\x1b\xed\x41\x6d\xee\x58\x76\x09\xc3\x7e\x4d\x71\xbd\x43\x52\x21\xce\xd3\x13\x92\x87\xf2\xd9\xc5\x44\xa6\xa1\x2c\xc5\x68\xb8\x3a\x78\x0c\x7b\x96\xc0\x2b\x9e\xe9\xe4\x21\x3b\x00\xe2\xcc\x25\xd8\x29\x2e\x32\x69\x42\x23\x06\xa4\x30\x39\xcf\x33\x67\x93\xb0\x26\x96\x93\xb9\x3a\x56\xba\x2e\x7e\x67\x5e\x4a\x31\xa2\x67\x46\xc4\x95\x12\xb9\x8d\xf6\x34\x65\x91\x16\x0f\xcc\x78\x9d\xb3\xe2\x95\xc6\xf0\x80\x87\x79\x05\xb7\x99\x37\xee\x36\x33\x08\x43\xdd\x10\xa3\x53\x0d\x8a\x13\xfc\xe9\x29\x1b\xd3\x64\xd0\xc9\xb8\x97\xc1\x84\x76\xa9\xc7\xb0\x87\x53\x53\x2b\xbe\x43\x4f\x90\x9e\xe2\x31\xdf\xae\x38\xab\xbd\x36\xe0\xa2\xb7\x90\x14\x7f\x1e\xfc\x59\x9e\xea\x16\xc5\xa5\x23\x29\x2c\x1e\x2e\x0a\xe9\xad\x87\xa9\xea\xd6\x37\xf0\xd2\x1b\xce\x45\x47\x79\xbe\x86\xe5\x51\xf4\x96\x34\x05\x0f\x18\x5f\x82\x8e\xc5\x60\x3c\x9a\xbd\xe2\x39\x26\x1a\xf2\xdd\xfe\xbf\xe2\x17\x42\xa4\x20\xf7\x81\x90\xcd\x8a\x97\x5f\x1b\xef\xd9\x8f\xa7\x6f\x1f\x0c\xf9\xb4\xe2\xe5\x4f\xcc\x38\x1c\xcb\x32\x40\x50\x9a\xcd\x24\xde\x0c\x25\x69\xbe\xdb\xfe\x6b\xdb\xa4\xc3\x2b\xb0\xa2\xeb\x96\xa3\xcf\x9d\x7a\x83\x2d\xdb\xb6\x78\xd4\xe4\xba\x4e\x0a\xb2\xef\x61\xa9\xcf\x27\xd9\xb6\xc5\x33\x12\x74\x1d\x2f\x2f\xa2\x14\xba\xfc\x67\xd8\xc4\x70\x68\xaa\xc1\xfd\x3e\x36\x81\xde\x97\x58\xce\xd5\xb7\x0d\xc1\xce\x5a\x96\x62\x31\x99\x14\xc3\xee\xa7\xdb\x1e\x21\x29\xc6\x27\xe1\x6f\x00\x00\x00\xff\xff\xdb\x54\x14\xfd\x23\x04\x00\x00")
+var _templates_conflicts_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x74\x54\x41\x6f\xdb\x3c\x0c\xbd\xf7\x57\xf0\x13\x7a\x6c\x6c\xf4\xf6\x61\x90\x0d\x6c\xed\x76\x1a\xb0\x62\xcd\x30\xec\xa8\x48\x74\x44\x44\x91\x3d\x89\x76\x11\x18\xfa\xef\x83\xed\x38\x71\xda\xe4\x24\x89\xef\x89\xe6\xa3\x1e\x2d\xff\x7b\xfe\xf1\xb4\xfe\xf3\xf2\x15\x2c\xef\x5d\x79\x27\x87\x05\x9c\xf2\xdb\x42\xa0\x17\xe5\x1d\x00\x80\xb4\xa8\xcc\xb4\x1d\x8f\x7b\x64\x05\xda\xaa\x10\x91\x0b\xd1\x72\xb5\xfa\x5f\xbc\x87\xbd\xda\x63\x21\x3a\xc2\xb7\xa6\x0e\x2c\x40\xd7\x9e\xd1\x73\x21\xde\xc8\xb0\x2d\x0c\x76\xa4\x71\x35\x1e\x1e\x80\x3c\x31\x29\xb7\x8a\x5a\x39\x2c\x1e\x1f\x20\xda\x40\x7e\xb7\xe2\x7a\x55\x11\x17\xbe\x5e\xa6\x67\x62\x87\xe5\x53\xed\x2b\x47\x9a\xa3\xcc\xa7\xc0\x99\xe0\xc8\xef\x20\xa0\x2b\x44\xe4\x83\xc3\x68\x11\x59\x80\x0d\x58\x15\xa2\xef\x1b\xb5\x45\x10\x91\x15\x93\xce\x47\x42\xa6\x63\x14\x29\xcd\x62\xf3\xb3\x5a\xb9\xa9\xcd\x61\xce\x2c\x0d\x75\xa0\x9d\x8a\xb1\x10\x7b\x45\xfe\x99\xba\x65\x59\xf6\x71\x59\x93\x7d\x5c\x40\x4d\xd9\xf7\x54\x41\x96\xd2\xda\x62\x44\x88\xb6\x0e\xac\x5b\x8e\xa0\x02\x82\xc1\x8a\x3c\x1a\x30\x54\x55\x18\xd0\xb3\x3b\xc0\xe6\x00\x11\x3b\x0c\xca\x41\xac\xdb\xa0\x31\x66\xb0\xb6\x08\xb5\x47\xd8\xab\xb0\x43\x03\xe4\xa1\x8d\x08\x6f\xe4\x23\xb4\x9e\xc9\x01\x0f\x04\xb6\x18\x22\xa8\x6d\x40\xcc\xfa\x1e\x5d\xc4\x94\x3e\xbb\x53\x9a\x33\xe2\x4d\x4a\x32\x6f\x4e\xea\x72\x43\xdd\x7c\x98\xcb\x9d\x31\x56\x1b\x87\xb3\xf6\x8d\x6b\x71\x3d\x04\x2e\x1e\xe5\xd2\x22\x43\x24\xcc\x17\x46\x4c\x5c\x82\xb6\x7c\x3d\x36\x41\xe6\x6c\x3f\x60\x63\xb1\xd7\x90\x6f\xad\x73\xf0\xeb\xe7\xf7\x6b\xd8\x87\x58\xce\x61\x51\x62\xfe\xae\x46\xc9\xcb\xd7\x9d\x64\x07\xe5\xb7\x38\x28\xef\xfb\x7b\x0d\x9f\x8a\x69\x7b\x8c\x3e\x63\x15\x53\x5a\xf0\xe5\x32\xff\x14\x30\xe5\x70\x33\x7b\x51\x6c\x87\xf6\xb2\xb9\x46\xc8\x26\x7d\xb7\x08\x52\x9d\xcc\x9a\xbd\x12\xe3\x60\xcd\xd3\x56\xe6\xaa\xbc\x95\x97\x2a\xc0\xbf\x70\xcc\x0e\xf7\x3a\xfb\x4d\xde\x63\x48\x69\xf2\xca\xe9\xd1\xd9\xdc\x6e\x13\xc0\x91\x76\x5c\x96\xfd\xbb\x98\x86\x7c\x34\xc5\xd9\x31\x4b\xb6\x6c\x96\x22\xa6\x89\x73\x14\x79\x9c\xb2\x2f\x4a\xef\x80\xeb\xf3\x14\x4c\x92\x9a\x79\xfc\xa6\xaf\xc8\x7c\xfa\x21\xfd\x1b\x00\x9d\x48\x33\x35\xa1\x04\x00\x00")
+
+func templates_conflicts_gohtml() ([]byte, error) {
+	return bindata_read(
+		_templates_conflicts_gohtml,
+		"templates/conflicts.gohtml",
+	)
+}
+
+var _templates_import_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x9c\x54\x41\x8f\xda\x3c\x10\xbd\xef\xaf\x98\xcf\xe2\x08\x44\x7b\xfb\x54\x39\x91\xda\x6e\x0f\x3d\x6d\xc5\xee\xa5\x47\x13\x4f\xf0\x08\x63\x47\xf6\x24\x14\x59\xfc\xf7\xca\x09\x61\x81\x76\xa5\xa5\x5c\xc8\xf3\x9b\x99\x37\xf3\x92\xb1\xfc\xef\xe9\xf9\xeb\xeb\xcf\x1f\xdf\xc0\xf0\xce\x56\x0f\x32\xff\x81\x55\x6e\x53\x0a\x74\xa2\x7a\x00\x00\x90\x06\x95\x1e\x1f\x07\xb8\x43\x56\x50\x1b\x15\x22\x72\x29\x3a\x6e\x16\xff\x8b\x5b\xda\xa9\x1d\x96\xa2\x27\xdc\xb7\x3e\xb0\x80\xda\x3b\x46\xc7\xa5\xd8\x93\x66\x53\x6a\xec\xa9\xc6\xc5\x00\xe6\x40\x8e\x98\x94\x5d\xc4\x5a\x59\x2c\x1f\xe7\x10\x4d\x20\xb7\x5d\xb0\x5f\x34\xc4\xa5\xf3\x97\xe5\x99\xd8\x62\xf5\x7d\x97\xeb\xca\x62\x44\x6f\xac\x25\xb7\x85\x80\xb6\x14\x91\x0f\x16\xa3\x41\x64\x01\x26\x60\x53\x8a\x94\x5a\xb5\x41\x10\x91\x15\x53\x5d\x0c\x01\xcb\x3a\x46\x71\x3c\x4e\x93\x16\x6f\xa3\xca\xb5\xd7\x87\xa9\xb2\xd4\xd4\x43\x6d\x55\x8c\xa5\xd8\x29\x72\x4f\xd4\x5f\xf6\x64\x1e\xab\x94\xa8\x81\xe5\x53\x38\xac\x3a\x77\x3c\x8e\xed\x41\x1b\x30\x5b\x90\x12\xda\x88\xd3\x69\x4a\xe8\xf4\xf1\x28\x0b\xf3\x78\x51\xa2\xad\x52\x5a\xe6\xd3\xf6\xac\x59\x68\xea\xcf\x80\xd5\xda\xe2\xd4\xc2\xda\x76\xf8\x9a\x0f\xae\x8c\xb9\x7e\x4d\xf9\x24\x4c\x09\x03\x27\xae\x49\x53\xbd\x18\x1f\xb8\xee\xb2\x8d\xe6\x96\x5b\x61\xec\xec\x9f\x4c\xc1\xe1\x42\xb2\xb8\xd1\x94\x7c\x69\x5a\xfe\xa5\x14\x94\xdb\x20\x2c\x3f\x6b\x8d\x79\x6a\x0e\x95\x64\x3d\x0d\xcb\xfa\x84\xa8\x81\xd9\xd9\xbd\xbd\xef\xac\x86\x35\x82\xca\x49\x93\x7b\x13\x70\x7a\xca\xbc\x6a\x06\xe0\xc4\xfd\x4d\xfd\xb9\xc7\xb0\x0f\xc4\x8c\xee\xae\x1e\xfc\x29\x0f\x81\x0d\x02\xfe\xa2\xc8\xe4\x36\x90\xbf\xb2\xa9\xad\x31\xc4\xbf\x13\xf2\x0f\xcd\xce\x9a\xe0\x77\x73\x98\xb1\x87\x4f\x25\x2c\x57\x98\x77\xe9\xca\xba\x21\xe2\x03\xf6\x45\xd5\xbf\xd9\x37\x81\xac\x0a\x2a\x42\x4a\x33\xf6\xf7\x3b\xf9\xb2\xa5\xb6\x7d\xff\x4d\xc6\x91\x9e\x83\xb2\x01\x95\x3e\x8c\x86\xc4\x7b\x55\x56\x18\x31\xf4\x1f\x90\x09\xa7\x40\x68\x7c\x00\x05\xeb\x8e\x2c\x2f\xc8\x41\xde\xf4\x0f\x89\xca\xe2\xea\x9b\x95\xc5\xb0\x68\x67\xd8\x56\x52\xdd\x5c\x1f\x96\x22\x0f\x57\xc6\x17\x55\x6f\x81\x3d\xc4\xd3\x1a\x45\x59\xa8\xea\xbc\xc0\xb2\x18\xeb\xca\x62\xbc\x5a\x7f\x0f\x00\x1d\xa0\x2b\x97\x6b\x05\x00\x00")
+
+func templates_import_gohtml() ([]byte, error) {
+	return bindata_read(
+		_templates_import_gohtml,
+		"templates/import.gohtml",
+	)
+}
+
+var _templates_list_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xcc\x59\x51\x6f\xdb\x38\x12\x7e\xef\xaf\x98\x23\xfa\xb0\x01\x6a\x29\xe9\xed\xee\x2d\x02\x49\x45\xe1\xa4\x40\x0f\xb9\xed\xa2\x49\x0b\xec\xbd\xd1\xe2\xd8\xe2\x9a\x22\xb5\xe4\xc8\x89\x61\xe8\xbf\x1f\x28\x59\xb6\x24\xdb\x89\xdc\xbb\xc3\xf6\x25\x96\x86\xf3\xcd\x50\xd4\x37\x1f\x87\x4a\xf4\xb7\x9b\x4f\xd3\x87\xdf\x7f\xbb\x85\x8c\x72\x95\xbc\x8a\xfc\x0f\x28\xae\x17\x31\x43\xcd\x92\x57\x00\x00\x51\x86\x5c\x34\x97\xf5\x6d\x8e\xc4\x21\xcd\xb8\x75\x48\x31\x2b\x69\x3e\xf9\x85\x0d\x87\x35\xcf\x31\x66\x2b\x89\x8f\x85\xb1\xc4\x20\x35\x9a\x50\x53\xcc\x1e\xa5\xa0\x2c\x16\xb8\x92\x29\x4e\xea\x9b\x37\x20\xb5\x24\xc9\xd5\xc4\xa5\x5c\x61\x7c\xf5\x06\x5c\x66\xa5\x5e\x4e\xc8\x4c\xe6\x92\x62\x6d\xba\xe1\x49\x92\xc2\xe4\x3e\x33\x96\xd2\x92\x5c\x14\x36\x86\xbd\x83\x92\x7a\x09\x16\x55\xcc\x1c\xad\x15\xba\x0c\x91\x18\x64\x16\xe7\x31\xdb\x6c\x0a\xbe\x40\x60\x8e\x38\xc9\x34\xac\x1d\x82\xd4\x39\x56\x55\xdd\x1c\x2e\xb5\xb2\x20\x70\x36\x3d\x80\xe4\xbc\x08\xfe\x68\xfc\xa3\xb0\xf1\xdb\xae\x52\xb8\x5f\xa6\x68\x66\xc4\xba\x8d\x17\x09\xb9\x82\x54\x71\xe7\x62\x96\x73\xa9\x6f\xe4\xaa\x9b\x2b\xbb\xea\x3e\x4c\x76\xd5\x19\x92\xba\x28\x09\x68\x5d\x60\xcc\x08\x9f\x88\xb5\x61\x1c\x72\x9b\x66\x0f\xb5\x49\x8a\xfe\xbd\xd1\x4b\x5c\x97\x45\xcc\xe6\x52\x11\xda\xcf\xe6\xd1\xfd\x70\xc1\xa0\x50\x3c\xc5\xcc\x28\x81\x36\x66\xf7\xb5\x3f\xb8\x36\x6f\x10\x74\x67\xd4\x99\x2f\x59\xae\xdd\x1c\x6d\x67\x18\xe0\xf6\xc9\xbf\xd3\xeb\x8e\x25\xe2\x83\xf5\xc5\xda\x85\x55\xd5\xbb\xb9\xb1\x39\xa7\xf8\x0f\x67\x34\x4b\xfe\x79\xff\xe9\xd7\x28\xe4\xc9\xd9\xd0\xc9\x5c\x71\x62\x89\xff\x0b\xdf\x14\x24\x75\x2b\x96\x4c\xef\xbf\x9e\x0d\x5c\xf3\x5c\xb1\xe4\xf7\xf7\xff\xba\x3b\x1b\x4a\xc6\x43\x1f\x3e\x7d\x03\xd4\x97\x21\x4b\x66\xc6\x2c\x73\x6e\x97\x6e\x88\xf7\x5e\xc0\x53\x92\x46\xef\x83\xc8\x7c\x1b\x84\x41\x8e\x94\x19\x11\xb3\xc2\x38\x62\x80\x3a\x6d\x38\x94\x97\x8a\x64\xc1\x2d\x85\x3e\xc0\x44\x70\xe2\xbd\x17\x3b\xa0\x5c\x26\x85\x40\xcd\xb6\xb5\x9c\x3a\x3b\x67\xb0\xe2\xaa\x44\x9f\x33\x98\xde\x7f\xfe\x50\x55\x03\xfc\xc7\x7a\x0e\xd7\xfd\x38\x73\xa9\xb0\x8d\xd2\x5c\x5b\xfc\xb3\x94\x16\xc5\x20\xb9\x43\x85\x29\xb5\xf9\x8c\x9e\x2b\x99\xd2\x20\x03\x40\x64\x0a\xff\xe0\xed\x54\xdc\x52\x16\x2c\xf1\x7f\x01\x9f\xa4\x23\xa9\x17\x51\xd8\xb8\xbc\x80\x34\x2b\xb4\x8f\x56\x12\xb2\x64\x77\x79\x6e\x0c\x8b\x7e\xb6\x2c\x69\x7e\x41\xe3\xe3\x71\x60\x14\x36\x0f\x37\xb0\x2a\x3e\x43\x95\xf4\x56\x2b\xcd\x30\x5d\xce\xcc\x53\xbb\x62\xc2\xae\x6d\xa9\x77\x2b\x7f\xc5\xa0\xf6\x40\x91\x80\xb0\x6b\xb0\xa5\x8e\xc2\x26\xcc\xe9\x37\xe9\xca\x59\x2e\x69\x17\xa3\x79\x4b\xbd\x85\x8d\x6a\x4a\xec\x2d\x51\x28\xe4\x6a\x7f\xbb\xd9\x3c\x4a\xca\x20\xf8\xd5\x90\x4c\xb1\xaa\xa2\xa2\x55\x08\x5d\x5b\x58\xb2\xd9\x04\x55\x15\x85\x45\xb2\xd9\xa0\x16\x55\x75\x00\xbd\xb5\xd6\xd8\x2e\x12\xbd\x61\x04\x70\x6a\xf4\x5c\xda\xbc\x33\xd2\xf0\x7f\x1b\x26\x6d\x86\xd9\x41\x3d\x28\xe9\x8e\x54\x43\xf2\xea\xc4\x1a\x3d\xcb\xf6\xd7\xc7\xe8\xfe\x0c\xbc\x99\xcb\x2e\x80\x40\x85\x84\x63\xc1\x05\xa7\xac\x5b\x69\xbf\x71\xca\xc6\xa7\x46\xe2\x8b\x2e\xfa\xf6\x81\x2f\xc6\xa3\x77\xab\xb9\x0d\xb0\x46\xd7\x87\x16\xc9\x4d\xfd\x30\x10\x39\xb2\x46\x2f\x92\x70\x37\xc3\x28\xdc\x9a\xde\xc0\x63\x26\xd3\x0c\x14\x72\xe1\x80\x0c\x6c\x36\xc1\xbd\x24\xac\xaa\x77\xf0\x90\x49\x07\x29\xd7\xda\x10\xcc\x10\x4a\x2d\x8c\xc6\xa0\x93\x61\xb3\x91\x73\x08\xee\xd7\x3a\x45\x51\x55\x1f\x09\x52\x93\xa3\x83\xb9\x35\x79\x1d\xc7\x94\xd6\x33\x10\xb8\x16\x60\x91\x4a\xab\x1d\x18\x0d\x94\x21\x68\x7c\x22\x70\x6b\x9d\x42\xa9\x15\x3a\x07\x16\x73\xb3\x42\xe1\x07\x2d\x02\x19\x13\x6c\x49\xe6\xf9\xf6\x6a\x5c\xb1\xdc\x1c\x79\x77\x43\xfd\x6e\xa9\x96\x4c\xb9\x4e\x51\xf5\xe4\x7a\x58\x59\x87\x34\xf7\xcf\x3b\xe5\xfa\x56\x48\x3a\x45\x72\x2e\xc4\x07\xf3\xff\x26\xf9\x7f\xc7\x71\x2e\x44\x0f\xf9\x5e\x88\xeb\x53\x81\x9a\x66\xe6\x38\xdb\xdf\x0b\xd1\x32\xbe\xdf\xb4\xb4\xdd\xca\xf1\xed\xe3\x64\xfc\xd2\xaa\x61\xf8\x2f\x9f\xef\x0e\xa2\x67\x44\x85\xbb\x0e\xc3\x20\x08\xce\x4c\x20\xb0\x69\x03\xbb\x8b\xb1\x4d\x74\xb3\x1f\x3a\x48\xd8\x85\x8d\xca\x43\x7c\xe1\x86\x09\x1e\xf8\xc2\x1d\x44\xf6\x8e\x6f\x7c\xd9\xe4\x1c\x1c\x16\xdc\x72\x42\x31\x2e\x47\x6a\x91\x93\xb1\xc3\x34\xd3\xc6\x7c\x90\xa9\x76\x47\x01\xb3\x35\x1b\x5b\x4e\xef\x7b\x34\x79\xbe\x38\x7a\x9b\x50\x44\x7c\xa6\xb0\x2d\x88\x99\x2a\xf1\xc1\x1b\xb6\x0d\x70\x73\xdd\x09\x4c\xfd\x43\x8b\xb7\xd8\x16\x5c\x8f\xf5\x67\x4c\x19\x18\x9d\x2a\x99\x2e\x63\xe6\x8c\xa5\x3a\xde\x0f\x97\x17\x6c\xd7\x9c\x47\x21\x65\x23\x20\x57\x17\x2c\xf9\x50\x2a\x05\x5f\x3e\xdf\x8d\x84\xbc\xbd\x60\x49\x87\x29\x23\x51\x7f\xbf\x60\x89\x7f\xfd\x23\xdd\x7f\xbc\x60\xc9\x57\xe9\x24\x8d\x05\xfc\x74\xc1\x92\x3b\xee\x08\x56\x1e\x85\x62\x24\xec\xe7\x0b\x96\x4c\x1b\x5a\x8c\x44\xfc\xa3\x45\x18\x3b\x12\xf1\x8b\x7f\x2d\xf5\x3e\x30\x04\x0c\xc4\x34\xa2\x2c\xa9\x5d\x86\xa2\xeb\xb9\x45\xb6\xcb\xc3\x01\x5f\x22\xea\x1e\xe1\x9a\xd0\x96\xeb\x05\x42\x70\x27\xf5\xd2\x55\xd5\x30\x2b\xfe\x09\xb5\x6e\xc1\xeb\x60\xa0\xe4\x3d\xee\xa1\x90\xbe\xbd\x1c\xf6\xdd\x24\x92\xce\x3e\x4a\xe2\x70\x78\xa4\xc0\xbd\x0e\xfc\x46\xd1\x4a\x9c\xaf\xae\x26\x67\x47\xd4\xce\x8c\x7f\x5c\xdf\xb6\x79\x06\x0a\xd7\xc9\x77\x6e\x9a\x81\xbc\x6d\xe3\xb7\x02\xf7\x62\xe0\x5d\x3f\x5a\xe6\x75\x4f\x39\x35\xa5\xa6\x53\x6b\x59\xbf\x30\x6d\x08\x02\xcf\xf0\xaf\x0d\xc1\x83\x8f\xee\xdf\x68\x4d\x55\x6d\x36\x3d\xf3\x87\xfa\x48\x06\xec\xed\xe5\xe5\xcf\x93\xcb\xab\xc9\xe5\x5b\xb8\xfa\xe9\xfa\xf2\x47\x56\x55\x5b\x5a\xbd\x94\x64\x5b\x0f\xdd\x04\xad\xe9\x30\xf8\xcb\x61\xf7\x72\x7c\xd2\xa3\xed\x92\x9e\x5d\xaa\x66\x03\x77\x87\x07\x2c\xbf\xd8\xb5\xac\x36\xc4\x39\xbf\xe7\xf8\x9f\xb4\xd7\xe7\x36\x20\x0d\x39\xce\x09\x30\xa2\xd1\x3e\xb7\xdd\xde\xd2\xf6\x48\xd3\xfd\xf2\xd6\x78\xcf\x57\x78\x0c\x72\x4e\xb7\x79\x7c\x5b\x6d\x6c\x7d\x2a\xf4\x05\xb0\xde\x7a\x95\xc3\xa1\x6c\x9d\x2f\x53\xfb\xd9\x6e\x7b\x7e\x96\xec\x2e\xfd\x54\x4f\x72\xb6\x27\x25\xa7\xbc\xb6\x1a\xdc\xc8\x42\xe4\x0a\xae\x77\x9b\x3a\x5f\xec\x0e\x93\xde\x9e\x3c\x57\x45\xdf\x9d\x5a\x40\xfd\x15\x33\x66\xfb\x5c\x5f\x0a\xd1\x13\x8d\xed\xbd\x3f\x03\xb5\x43\x2f\xe7\x62\xdf\xa9\x0a\xd5\xb3\x7a\x7d\xe4\xd4\x33\x56\xa2\x4e\xd4\xc4\x3b\x2f\x02\x71\xa7\x94\x7d\xf8\x63\x05\x72\xf4\x0b\xda\x77\x2a\x6c\x47\xbe\x1e\xfc\x15\xd2\xf6\x6d\xa2\x76\x73\x7c\xf2\x63\x14\xea\xf0\xac\x7c\x54\xb5\x06\x1e\x43\x4b\x14\xf6\x5a\xb9\x28\xac\xcf\x11\xed\x37\xfb\x66\x28\x0a\x9b\x7f\x7f\xfc\x67\x00\xa0\x43\x02\x18\x0f\x19\x00\x00")
 
 func templates_list_gohtml() ([]byte, error) {
 	return bindata_read(
@@ -61,6 +88,24 @@ func templates_list_gohtml() ([]byte, error) {
 	)
 }
 
+var _templates_misses_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\xd4\x56\xdf\x6f\xdb\x36\x10\x7e\xcf\x5f\x71\x23\xf6\x58\x9b\x76\xb0\x0d\x43\x41\xe9\x61\x6e\x03\x14\xc8\xb6\xa2\x29\x30\x6c\x6f\x67\xe9\x6c\x12\xa1\x48\x95\x3c\x39\x0d\x04\xfd\xef\x83\x24\xcb\xb6\x64\xa7\x4d\x51\x20\xd8\x9e\x14\xde\x77\xfc\x78\x3f\xbe\x9c\x4f\xfd\xf0\xe6\xcf\xd5\xc7\xbf\xdf\xbf\x05\xcd\x85\x4d\xaf\x54\xfb\x01\x8b\x6e\x9b\x08\x72\x22\xbd\x02\x00\x50\x9a\x30\xef\xff\xec\x8e\x05\x31\x42\xa6\x31\x44\xe2\x44\x54\xbc\x99\xfd\x2a\xa6\xb0\xc3\x82\x12\xb1\x33\xf4\x50\xfa\xc0\x02\x32\xef\x98\x1c\x27\xe2\xc1\xe4\xac\x93\x9c\x76\x26\xa3\x59\x77\x78\x05\xc6\x19\x36\x68\x67\x31\x43\x4b\xc9\xf2\x15\x44\x1d\x8c\xbb\x9f\xb1\x9f\x6d\x0c\x27\xce\x9f\xd2\xb3\x61\x4b\xe9\xef\x3e\x32\x3c\xa0\x63\xca\x95\xec\x4d\x47\x17\x6b\xdc\x3d\x04\xb2\x89\x88\xfc\x68\x29\x6a\x22\x16\xa0\x03\x6d\x12\x51\xd7\x25\x6e\x09\x44\x64\x64\x93\xc9\xce\x61\x9e\xc5\x28\x9a\x66\x48\x57\x1e\xf3\x55\x6b\x9f\x3f\x0e\xcc\x2a\x37\x3b\xc8\x2c\xc6\x98\x88\x02\x8d\x7b\x63\x76\xa7\x81\xe9\xe5\x38\x2a\xbd\x3c\x82\x75\x6d\x36\x30\x7f\x1b\x82\x0f\x4d\xa3\xca\x81\x85\x5a\x83\x48\xeb\xfa\x00\xc9\x32\xad\x6b\x72\x79\xd3\x1c\x89\xcb\xb4\xbf\xfe\x57\x47\xdc\x34\x77\xda\x07\xce\x2a\x8e\x50\x92\x2f\x2d\x01\xc6\x7b\xca\x61\xe3\x03\xb0\x46\x86\xdc\x83\xf3\x0c\xf4\xd9\x44\x86\x47\xe2\x79\x5d\x93\x8d\xd4\x34\x7f\xf8\x36\x1d\xd0\x18\x4f\xae\x20\x14\x26\x46\xe3\xb6\x10\xf7\xbc\xf3\x7d\x04\x6d\x30\x43\xea\x32\x37\xbb\xe1\x30\x8e\x66\xf0\x60\x5c\x5b\x1a\x12\x5b\xdb\x8a\x3e\xb6\x86\x51\xe7\xc6\x3a\x6a\x2d\x61\xb8\xd0\x61\x62\x0c\xea\x74\xc8\x54\x49\xd6\x53\xec\x03\x7d\xaa\x28\x72\xbc\x84\xdd\x98\x10\x19\x22\x91\xbb\x84\xde\xe2\x17\xc0\x55\x20\x64\xba\x84\x9c\xd9\x24\x87\x93\xec\xe4\x24\x3d\xc5\xa7\xda\xe9\xeb\x16\xd0\x6d\xe9\xac\x74\xfb\x52\x9c\xba\xb6\x86\xbc\xd5\xc5\x7b\x64\xdd\x76\x82\xf3\x33\x78\x28\x9d\xab\x8a\x4e\x42\x2b\x5f\x39\x7e\xc2\xb7\x57\x50\xab\x8a\x79\x57\x9b\xf9\xbb\xf8\x0f\x05\xdf\x34\x75\xbd\x37\xdc\xf8\x50\x20\x83\xb8\x5e\x2c\x7e\x99\x2d\x96\xb3\xc5\x35\x2c\x7f\x7e\xbd\xf8\x49\x34\xcd\x41\x0e\x5f\x26\xbe\xc5\x31\xef\x2d\x7e\x07\xed\xc8\x00\xa0\x36\x3e\x14\x50\x10\x6b\x9f\x27\xa2\xf4\x91\xc5\xd4\x05\x40\x19\x57\x56\x0c\xfc\x58\x52\x22\xb4\xc9\x73\x72\x62\x3f\x88\xb2\x18\x36\x02\x76\x68\x2b\x6a\x47\xc0\x8f\xf3\xd5\xdd\x87\x9b\xc3\x7f\xfc\x33\x49\x30\x63\xe3\xdd\x81\x26\xeb\x94\xf2\x6d\x14\x25\xb2\x3e\x89\x63\xdf\xde\xaf\x71\x30\x7d\xe6\x81\xa1\x0a\x56\x40\x69\x31\x23\xed\x6d\x4e\x21\x11\x9a\xb9\x8c\xaf\xa5\x14\x10\xe8\x53\x65\x02\xe5\x5f\xa1\x8b\xd5\xba\x30\x7c\x08\x63\x75\x39\x0f\x25\xdb\x9a\x4f\x3a\xf3\x7f\xed\x95\xd9\x3a\x1f\x5e\xa4\x57\x93\xe2\xbe\xbb\xfc\xf0\x73\x8a\x3b\x9e\x2f\x00\x67\xbf\x0b\x72\x34\x61\x94\xec\x26\x70\x7a\x75\xc9\xbb\x1f\xd9\x7d\x30\x47\xab\xd2\xd7\xe9\xde\xa6\xa4\xbe\x4e\xbf\x61\x94\x3f\x39\xdb\xa6\x6f\xbc\xc4\x70\xfb\x2f\x0a\xf0\xf9\xea\x59\x57\xcc\xde\x4d\xe4\x73\x51\xc5\x95\x1b\x74\x7c\xa7\xfd\x03\xe0\x16\x8d\x53\xb2\xbf\xff\x5d\xc4\x1b\x1f\xb6\xc4\x22\xbd\xe9\xbe\x4f\x51\xbe\xb4\x68\x55\x99\x2a\x9c\xac\x6d\xd6\x44\xee\x56\xb5\xdf\x30\xbb\x07\xf6\x87\xa5\x25\x2a\x89\xe9\x61\x63\x51\xb2\x7f\x45\xc9\x7e\xaf\xfd\x77\x00\xb4\x4a\x5c\x14\xe8\x0a\x00\x00")
+
+func templates_misses_gohtml() ([]byte, error) {
+	return bindata_read(
+		_templates_misses_gohtml,
+		"templates/misses.gohtml",
+	)
+}
+
+var _templates_notfound_gohtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x7c\x95\xcf\x6e\xc3\x28\x10\xc6\xef\x7d\x8a\x59\x4e\xbb\x52\x53\xd4\x9e\x56\x15\xb6\xb4\x4a\xba\xa7\x6a\xb7\xea\x9f\xc3\x1e\x89\x19\x07\x54\x0c\x5e\x18\xa7\x8d\x10\xef\xbe\xb2\x9d\xd8\x4e\xbb\xe9\x29\x06\x86\xdf\xf7\x0d\xc3\x10\xf1\xcb\xe6\xef\xf5\xeb\x3f\x4f\x0f\xa0\xa9\xb1\xe5\x95\xe8\x7f\xc0\x4a\xb7\x2b\x18\x3a\x56\x5e\x01\x00\x08\x8d\x52\x8d\x9f\xc3\xb0\x41\x92\x50\x69\x19\x22\x52\xc1\x3a\xaa\x57\xbf\xb3\xaf\xcb\x4e\x36\x58\xb0\xbd\xc1\x8f\xd6\x07\x62\x50\x79\x47\xe8\xa8\x60\x1f\x46\x91\x2e\x14\xee\x4d\x85\xab\x61\x70\x0d\xc6\x19\x32\xd2\xae\x62\x25\x2d\x16\xb7\xd7\x10\x75\x30\xee\x7d\x45\x7e\x55\x1b\x2a\x9c\x5f\xe2\xc9\x90\xc5\xf2\x2f\x4f\x50\xfb\xce\x29\xc1\xc7\x89\x39\xc0\x1a\xf7\x0e\x01\x6d\xc1\x22\x1d\x2c\x46\x8d\x48\x0c\x74\xc0\xba\x60\x29\xb5\x72\x87\xc0\x22\x49\x32\x15\x1f\x02\x6e\xaa\x18\x59\xce\xa7\x64\xf9\x9c\xad\xd8\x7a\x75\x38\x91\x85\x32\x7b\xa8\xac\x8c\xb1\x60\x8d\x34\x6e\x63\xf6\x4b\x5b\xfa\xb6\x4c\xc9\xd4\x70\xf3\x24\x49\xe7\xcc\x53\x3a\x7e\x81\xf2\x18\xc1\x79\x02\xfc\x34\x91\x52\x42\x1b\x31\xe7\x29\x81\x94\xd0\xa9\x9c\x05\xd7\xb7\x33\x6e\x44\xbd\x74\xbb\x1d\x46\x32\xde\xc5\x9c\x67\xa9\xb6\xdc\x18\x05\x07\xdf\x41\x83\xd2\xdd\x0b\xde\x2e\x7c\x74\x76\x1e\xf4\x9c\x20\xdd\x0e\x2f\xa1\x86\xd3\x2a\x85\x9c\x4e\x67\x38\xbb\xa3\x71\x56\xce\x49\x08\x2e\x4b\x48\xe9\xe6\xed\xf9\x31\xe7\xd1\xdc\x06\x63\x15\x4c\xdb\x23\x73\x86\x5f\x53\x3a\x9f\xf9\x6d\xca\xcb\x9a\x73\x47\xc3\xf4\x6c\x98\x2f\x1d\x7f\x5d\x1d\xa5\xd6\xd2\xad\x03\x4a\xc2\xe5\xbe\xda\x87\x06\x64\xd5\xab\xcd\x75\xad\x86\xb0\xbe\x9a\xd0\x20\x69\xaf\x0a\xd6\xfa\x48\x6c\xe9\x40\xe8\xbb\x72\xc4\x81\x21\xc1\xf5\xdd\xb9\xbd\x5e\xf0\x21\x04\x1f\x72\x16\xed\xa9\xe0\xd8\x4f\xb0\x32\xa5\x69\x89\xb7\xe5\x57\xb3\x00\xc2\xca\x2d\xda\xf2\x45\xfb\x40\x55\x47\x20\x8c\x6b\x3b\x02\x3a\xb4\x58\x30\xc2\x4f\x62\xc7\xc6\x68\x25\x69\x06\x7b\x69\x3b\x2c\xd8\x74\xca\x0c\x02\xfe\xdb\x99\x80\xaa\x14\x7c\x44\x7d\x87\xbf\x3d\x3f\x5e\xe6\x76\xc1\x2e\xb1\x43\xb9\x18\xb4\x56\x56\xa8\xbd\x55\x18\x0a\xa6\x89\xda\x78\xcf\xf9\x2c\x06\xb2\x23\x5f\xfb\xaa\x8b\x3f\xc8\x2e\x8a\x7b\x59\x5e\xcd\x41\x4b\x1b\x67\x17\x83\xfd\x20\xf2\x2a\x77\xf1\x32\x9d\xe4\x2e\x2e\xb1\x7d\xf4\xb7\xf4\x2a\xdf\x34\x12\x22\xb6\x32\x48\x42\xf5\x93\xda\x78\x09\x14\x6c\x0f\x97\x35\x87\x0b\xe5\xc3\x52\x76\x3d\x4e\x5d\xca\x64\x49\xd2\x46\x29\x74\x13\x2b\x86\xfa\x0c\xf4\xf2\xfc\xe7\xf4\xee\xfc\xcf\xee\xd8\x6d\x1b\x43\xd3\x8e\xd1\xee\x22\x5c\xf0\xbe\x07\x2e\x35\x8f\xe0\xca\xec\x4f\x8b\xa2\x5d\x76\xf9\xd8\x2b\xd6\x44\x1a\xde\xbd\x3f\xac\x85\x78\xbc\xb2\xb1\xef\xf4\xe9\x45\x11\x7c\x7c\x01\x05\x1f\xff\x1e\xfe\x1b\x00\xac\x0f\xa2\x6c\x2f\x06\x00\x00")
+
+func templates_notfound_gohtml() ([]byte, error) {
+	return bindata_read(
+		_templates_notfound_gohtml,
+		"templates/notfound.gohtml",
+	)
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -83,10 +128,15 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() ([]byte, error){
-	"static/map.js":         static_map_js,
-	"static/searchicon.png": static_searchicon_png,
-	"static/style.css":      static_style_css,
-	"templates/list.gohtml": templates_list_gohtml,
+	"static/map.js":              static_map_js,
+	"static/openapi.json":        static_openapi_json,
+	"static/searchicon.png":      static_searchicon_png,
+	"static/style.css":           static_style_css,
+	"templates/conflicts.gohtml": templates_conflicts_gohtml,
+	"templates/import.gohtml":    templates_import_gohtml,
+	"templates/list.gohtml":      templates_list_gohtml,
+	"templates/misses.gohtml":    templates_misses_gohtml,
+	"templates/notfound.gohtml":  templates_notfound_gohtml,
 }
 
 // AssetDir returns the file names below a certain
@@ -132,10 +182,15 @@ type _bintree_t struct {
 var _bintree = &_bintree_t{nil, map[string]*_bintree_t{
 	"static": &_bintree_t{nil, map[string]*_bintree_t{
 		"map.js":         &_bintree_t{static_map_js, map[string]*_bintree_t{}},
+		"openapi.json":   &_bintree_t{static_openapi_json, map[string]*_bintree_t{}},
 		"searchicon.png": &_bintree_t{static_searchicon_png, map[string]*_bintree_t{}},
 		"style.css":      &_bintree_t{static_style_css, map[string]*_bintree_t{}},
 	}},
 	"templates": &_bintree_t{nil, map[string]*_bintree_t{
-		"list.gohtml": &_bintree_t{templates_list_gohtml, map[string]*_bintree_t{}},
+		"conflicts.gohtml": &_bintree_t{templates_conflicts_gohtml, map[string]*_bintree_t{}},
+		"import.gohtml":    &_bintree_t{templates_import_gohtml, map[string]*_bintree_t{}},
+		"list.gohtml":      &_bintree_t{templates_list_gohtml, map[string]*_bintree_t{}},
+		"misses.gohtml":    &_bintree_t{templates_misses_gohtml, map[string]*_bintree_t{}},
+		"notfound.gohtml":  &_bintree_t{templates_notfound_gohtml, map[string]*_bintree_t{}},
 	}},
 }}
@@ -0,0 +1,213 @@
+// Package client manages the links of a map server through its JSON API.
+//
+//	c := client.New("http://localhost:8080", token)
+//	l, err := c.Create(ctx, client.Link{Path: "gh", URL: "https://github.com"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LinksPath is where the server serves the link API
+const LinksPath = "/api/v1/links"
+
+// Link is a shortcut as the API sends and accepts it. Visits, Created,
+// Updated and LastVisited are kept by the server and ignored when sent.
+type Link struct {
+	Path        string    `json:"path"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Creator     string    `json:"creator,omitempty"`
+	Visits      int       `json:"visits,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+	Updated     time.Time `json:"updated,omitempty"`
+	LastVisited time.Time `json:"last_visited,omitempty"`
+	PathMode    string    `json:"path_mode,omitempty"`
+	QueryMode   string    `json:"query_mode,omitempty"`
+	Fragment    string    `json:"fragment,omitempty"`
+	Params      string    `json:"params,omitempty"`
+	Generated   bool      `json:"generated,omitempty"` // made by POST /shorten
+
+	// ETag is the version of the link the server returned it at. Update
+	// and Delete send it back so they fail with ErrChanged when the link
+	// was changed since.
+	ETag string `json:"-"`
+}
+
+// Errors that an *Error matches with errors.Is, by status
+var (
+	ErrUnauthorized = errors.New("unauthorized")   // 401 and 403
+	ErrNotFound     = errors.New("not found")      // 404
+	ErrExists       = errors.New("already exists") // 409
+	ErrChanged      = errors.New("changed")        // 412
+	ErrInvalid      = errors.New("invalid link")   // 400 and 422
+)
+
+// Error is an error response from the server
+type Error struct {
+	Status  int    `json:"status"`
+	Message string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("map: %v %v", e.Status, e.Message)
+}
+
+// Is matches the Err values for the status of e
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrExists:
+		return e.Status == http.StatusConflict
+	case ErrChanged:
+		return e.Status == http.StatusPreconditionFailed
+	case ErrInvalid:
+		return e.Status == http.StatusBadRequest || e.Status == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// Client calls the link API of one server
+type Client struct {
+	// BaseURL is the address of the server, such as http://map:8080, with
+	// its base path and page prefix if it has them: http://example.com/go/_
+	BaseURL string
+	// Token is the admin token, needed to change links
+	Token string
+	// HTTPClient sends the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the server at baseURL
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token}
+}
+
+// List returns every link, or those carrying tag when it is not empty
+func (c *Client) List(ctx context.Context, tag string) ([]Link, error) {
+	u := LinksPath
+	if tag != "" {
+		u += "?" + url.Values{"tag": {tag}}.Encode()
+	}
+	var links []Link
+	_, err := c.do(ctx, http.MethodGet, u, nil, nil, &links)
+	return links, err
+}
+
+// Get returns the link at path
+func (c *Client) Get(ctx context.Context, path string) (*Link, error) {
+	return c.link(ctx, http.MethodGet, path, nil, nil)
+}
+
+// Create adds l, failing with ErrExists when its path is taken
+func (c *Client) Create(ctx context.Context, l Link) (*Link, error) {
+	l2 := new(Link)
+	h, err := c.do(ctx, http.MethodPost, LinksPath, nil, l, l2)
+	if err != nil {
+		return nil, err
+	}
+	l2.ETag = h.Get("ETag")
+	return l2, nil
+}
+
+// Update replaces the link at l.Path with l, creating it if needed. When
+// l.ETag is set it fails with ErrChanged if the link changed since.
+func (c *Client) Update(ctx context.Context, l Link) (*Link, error) {
+	hdr := http.Header{}
+	if l.ETag != "" {
+		hdr.Set("If-Match", l.ETag)
+	}
+	return c.link(ctx, http.MethodPut, l.Path, hdr, l)
+}
+
+// Delete removes the link at path. When etag is set it fails with
+// ErrChanged if the link changed since.
+func (c *Client) Delete(ctx context.Context, path, etag string) error {
+	hdr := http.Header{}
+	if etag != "" {
+		hdr.Set("If-Match", etag)
+	}
+	_, err := c.do(ctx, http.MethodDelete, linkPath(path), hdr, nil, nil)
+	return err
+}
+
+func (c *Client) link(ctx context.Context, method, path string, hdr http.Header, body interface{}) (*Link, error) {
+	l := new(Link)
+	h, err := c.do(ctx, method, linkPath(path), hdr, body, l)
+	if err != nil {
+		return nil, err
+	}
+	l.ETag = h.Get("ETag")
+	return l, nil
+}
+
+// linkPath is the API path of the link at path, escaped segment by segment
+func linkPath(path string) string {
+	return LinksPath + "/" + (&url.URL{Path: strings.Trim(path, "/")}).EscapedPath()
+}
+
+// do sends a request with body encoded as JSON and decodes the response
+// into out, returning its headers
+func (c *Client) do(ctx context.Context, method, path string, hdr http.Header, body, out interface{}) (http.Header, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		e := &Error{Status: resp.StatusCode}
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(b, e) != nil || e.Message == "" {
+			e.Message = strings.TrimSpace(string(b))
+		}
+		e.Status = resp.StatusCode
+		return resp.Header, e
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.Header, fmt.Errorf("map: cannot read the response: %v", err)
+		}
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"urlshort"
+	"urlshort/client"
+	"urlshort/persist"
+)
+
+const token = "s3cret"
+
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// newServer serves an empty in-memory store
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	table, err := urlshort.NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(urlshort.SetHandler(table, urlshort.Options{AdminToken: token}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	srv := newServer(t)
+	c := client.New(srv.URL, token)
+	ctx := context.Background()
+
+	created, err := c.Create(ctx, client.Link{Path: "gh", URL: "https://github.com", Tags: []string{"code"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.URL != "https://github.com" || created.ETag == "" || created.Created.IsZero() {
+		t.Errorf("Create returned %+v", created)
+	}
+	if _, err := c.Create(ctx, client.Link{Path: "gh", URL: "https://github.com"}); !errors.Is(err, client.ErrExists) {
+		t.Errorf("second Create: %v, want ErrExists", err)
+	}
+	if _, err := c.Create(ctx, client.Link{Path: "nyt", URL: "https://www.nytimes.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Get(ctx, "gh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ETag != created.ETag {
+		t.Errorf("Get ETag = %v, want %v", got.ETag, created.ETag)
+	}
+	if _, err := c.Get(ctx, "nope"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Get of a missing link: %v, want ErrNotFound", err)
+	}
+
+	all, err := c.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Path != "gh" || all[1].Path != "nyt" {
+		t.Errorf("List = %+v, want gh and nyt", all)
+	}
+	tagged, err := c.List(ctx, "code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 1 || tagged[0].Path != "gh" {
+		t.Errorf("List(code) = %+v, want gh", tagged)
+	}
+
+	got.Description = "Code hosting"
+	updated, err := c.Update(ctx, *got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Description != "Code hosting" || updated.ETag == got.ETag {
+		t.Errorf("Update returned %+v", updated)
+	}
+	// got still carries the ETag from before the update
+	if _, err := c.Update(ctx, *got); !errors.Is(err, client.ErrChanged) {
+		t.Errorf("Update with a stale ETag: %v, want ErrChanged", err)
+	}
+	if err := c.Delete(ctx, "gh", got.ETag); !errors.Is(err, client.ErrChanged) {
+		t.Errorf("Delete with a stale ETag: %v, want ErrChanged", err)
+	}
+	if err := c.Delete(ctx, "gh", updated.ETag); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(ctx, "gh", ""); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Delete of a missing link: %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	anon := client.New(srv.URL, "")
+	if _, err := anon.Create(ctx, client.Link{Path: "gh", URL: "https://github.com"}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Create without the token: %v, want ErrUnauthorized", err)
+	}
+	if _, err := anon.List(ctx, ""); err != nil {
+		t.Errorf("List without the token: %v", err)
+	}
+
+	c := client.New(srv.URL, token)
+	if _, err := c.Create(ctx, client.Link{Path: "gh"}); !errors.Is(err, client.ErrInvalid) {
+		t.Errorf("Create without a URL: %v, want ErrInvalid", err)
+	}
+	for _, site := range []string{"github.com", "javascript:alert(1)"} {
+		if _, err := c.Create(ctx, client.Link{Path: "gh", URL: site}); !errors.Is(err, client.ErrInvalid) {
+			t.Errorf("Create of %q: %v, want ErrInvalid", site, err)
+		}
+	}
+	var e *client.Error
+	if _, err := c.Get(ctx, "nope"); !errors.As(err, &e) || e.Status != 404 || e.Message == "" {
+		t.Errorf("Get of a missing link: %#v, want a 404 *Error with a message", err)
+	}
+}
@@ -0,0 +1,219 @@
+package urlshort
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Cookies of the list page
+const (
+	// csrfCookie holds the token every form of the list page sends back,
+	// so other sites cannot post them on a visitor's behalf
+	csrfCookie = "map_csrf"
+	// flashCookie carries the notice shown after a change redirects back
+	// to the list
+	flashCookie = "map_flash"
+)
+
+// csrfToken returns the token of the visitor, issuing one if needed
+func csrfToken(w http.ResponseWriter, r *http.Request, opts Options) string {
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value != "" {
+		return c.Value
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Failed to make a CSRF token: %v", err)
+		return ""
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     opts.page(""),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return token
+}
+
+// checkCSRF reports a form posted without the token of the visitor
+func checkCSRF(r *http.Request) error {
+	c, err := r.Cookie(csrfCookie)
+	if err != nil || c.Value == "" || subtle.ConstantTimeCompare([]byte(c.Value), []byte(r.FormValue("csrf"))) != 1 {
+		return apiErrorf(http.StatusForbidden, "the form has expired, reload the page and try again")
+	}
+	return nil
+}
+
+// setFlash keeps msg for the next page the visitor loads
+func setFlash(w http.ResponseWriter, opts Options, msg string) {
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Value: url.QueryEscape(msg), Path: opts.page("list"), HttpOnly: true})
+}
+
+// takeFlash returns the message left by setFlash and clears it
+func takeFlash(w http.ResponseWriter, r *http.Request, opts Options) string {
+	c, err := r.Cookie(flashCookie)
+	if err != nil {
+		return ""
+	}
+	http.SetCookie(w, &http.Cookie{Name: flashCookie, Path: opts.page("list"), MaxAge: -1, HttpOnly: true})
+	msg, _ := url.QueryUnescape(c.Value)
+	return msg
+}
+
+// linkForm holds the values of the add and edit forms
+type linkForm struct {
+	Path        string
+	URL         string
+	Description string
+	Tags        string // comma separated
+	Creator     string // only asked for when adding
+	ETag        string // version of the link the form was loaded with
+}
+
+func formOf(s persist.Short) linkForm {
+	return linkForm{Path: s.Path, URL: s.Site, Description: s.Description, Tags: strings.Join(s.Tags, ", "), ETag: etag(s)}
+}
+
+// tags splits the tags field, dropping empty ones
+func (f linkForm) tags() []string {
+	var tags []string
+	for _, t := range strings.Split(f.Tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// listAction applies the add, edit or delete posted from the list page and
+// returns the notice to show. The page is filled in to show the form
+// again, or the deletion to confirm, when there is no notice.
+func listAction(table *Table, r *http.Request, page *listPage) (string, error) {
+	if err := checkCSRF(r); err != nil {
+		return "", err
+	}
+	form := linkForm{
+		Path:        strings.Trim(strings.TrimSpace(r.FormValue("path")), "/"),
+		URL:         strings.TrimSpace(r.FormValue("url")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		Tags:        r.FormValue("tags"),
+		Creator:     strings.TrimSpace(r.FormValue("creator")),
+		ETag:        r.FormValue("etag"),
+	}
+	p := form.Path
+	switch r.FormValue("action") {
+	case "add":
+		page.Add = form
+		s := persist.Short{Path: p, Site: form.URL, Description: form.Description, Tags: form.tags(), Creator: form.Creator}
+		if user, _, ok := r.BasicAuth(); ok && s.Creator == "" {
+			// The name typed at the password prompt
+			s.Creator = strings.TrimSpace(user)
+		}
+		if err := checkLink(s); err != nil {
+			return "", err
+		}
+		if err := checkAbsolute(s.Site); err != nil {
+			return "", err
+		}
+		if err := table.checkReserved(p); err != nil {
+			return "", err
+		}
+		err := table.SaveIf(s, func(old persist.Short, exists bool) error {
+			if exists {
+				return apiErrorf(http.StatusConflict, "/%v already exists, edit it below instead", p)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		log.Printf("Created %v -> %v", s.Path, s.Site)
+		return "Added /" + p + ".", nil
+
+	case "edit":
+		page.Edit, page.Form = p, form
+		old, ok := table.Lookup(p)
+		if !ok {
+			return "", apiErrorf(http.StatusNotFound, "/%v no longer exists", p)
+		}
+		s := old
+		s.Site, s.Description, s.Tags = form.URL, form.Description, form.tags()
+		// Leave the counts to the store, the snapshot lags behind them
+		s.Count, s.LastVisited = 0, time.Time{}
+		if err := checkLink(s); err != nil {
+			return "", err
+		}
+		if s.Site != old.Site {
+			if err := checkAbsolute(s.Site); err != nil {
+				return "", err
+			}
+		}
+		err := table.SaveIf(s, func(cur persist.Short, exists bool) error {
+			if !exists {
+				return apiErrorf(http.StatusNotFound, "/%v no longer exists", p)
+			}
+			if etag(cur) != form.ETag {
+				return errChanged(p)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		log.Printf("Updated %v -> %v", s.Path, s.Site)
+		return "Saved /" + p + ".", nil
+
+	case "delete":
+		old, ok := table.Lookup(p)
+		if !ok {
+			return "", apiErrorf(http.StatusNotFound, "/%v no longer exists", p)
+		}
+		if etag(old) != form.ETag {
+			return "", errChanged(p)
+		}
+		if r.FormValue("confirm") == "" {
+			page.Confirm = &listEntry{old, table.Origin(p), form.ETag}
+			return "", nil
+		}
+		err := table.DeleteIf(p, func(cur persist.Short) error {
+			if etag(cur) != form.ETag {
+				return errChanged(p)
+			}
+			return nil
+		})
+		if errors.Is(err, persist.ErrNotFound) {
+			return "", apiErrorf(http.StatusNotFound, "/%v no longer exists", p)
+		}
+		if err != nil {
+			return "", err
+		}
+		log.Printf("Deleted %v", p)
+		return "Deleted /" + p + ".", nil
+	}
+	return "", apiErrorf(http.StatusBadRequest, "unknown action %q", r.FormValue("action"))
+}
+
+// checkAbsolute reports a URL typed without its scheme or host, which
+// would redirect relative to the server itself
+func checkAbsolute(site string) error {
+	if u, err := url.Parse(paramRe.ReplaceAllString(site, "x")); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL, start it with https://", site)
+	}
+	return nil
+}
+
+func errChanged(p string) error {
+	return apiErrorf(http.StatusConflict, "/%v was changed since the page was loaded, reload it and try again", p)
+}
@@ -8,4 +8,5 @@ require (
 	github.com/fsnotify/fsnotify v1.4.9
 	github.com/kr/pretty v0.2.0 // indirect
 	golang.org/x/sys v0.0.0-20200625212154-ddb9806d33ae // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
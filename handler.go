@@ -2,15 +2,13 @@ package urlshort
 
 import (
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"html/template"
 	"log"
 	"net/http"
-	"os"
 	"path"
 	"strings"
-	"text/template"
 
 	"urlshort/assets"
 	"urlshort/persist"
@@ -22,13 +20,22 @@ import (
 // implements http.Handler) that will attempt to map any
 // paths (keys in the map) to their corresponding URL (values
 // that each key in the map points to, in string format).
+// URLs may take arguments from the rest of the path, see resolve.
 // If the path is not provided in the map, then the fallback
 // http.Handler will be called instead.
 func MapHandler(pathsToUrls map[string]string, fallback http.Handler) http.HandlerFunc {
+	links := make(map[string]persist.Short, len(pathsToUrls))
+	for p, site := range pathsToUrls {
+		links[p] = persist.Short{Path: p, Site: site}
+	}
+	rules := compileRules(links)
+	lookup := func(p string) (persist.Short, bool) {
+		s, ok := links[p]
+		return s, ok
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		urlpath := strings.TrimLeft(r.URL.Path, "/")
-		if path, ok := pathsToUrls[urlpath]; ok {
-			http.Redirect(w, r, path, http.StatusFound)
+		if _, target, ok := resolve(lookup, rules, r.URL); ok {
+			http.Redirect(w, r, target, http.StatusFound)
 			return
 		}
 		// Match paths in the map else
@@ -36,30 +43,94 @@ func MapHandler(pathsToUrls map[string]string, fallback http.Handler) http.Handl
 	}
 }
 
-// SetHandler parses the file contents intp a map and returns a http.HandlerFunc
-// capable of serving requests
-func SetHandler(mfile string) http.HandlerFunc {
-	// var err error
-	mux := defaultMux()
-	return dbHandler(mux)
-	// pathUrls, err = parseJSON(mfile)
-	// if err != nil {
-	// 	log.Panicf("Error getting map JSON: %v", err)
-	// }
+// YAMLHandler parses the YAML in yml, either a mapping of paths to URLs
+// or a list of links as written by Export, and returns a MapHandler
+// serving them. Parse errors report the offending line.
+//
+//	/nyt: https://www.nytimes.com
+//
+//	- path: nyt
+//	  url: https://www.nytimes.com
+//	  tags: [news]
+func YAMLHandler(yml []byte, fallback http.Handler) (http.HandlerFunc, error) {
+	return decodeHandler(yml, FormatYAML, fallback)
+}
 
-	// return MapHandler(pathUrls, mux)
+// TOMLHandler parses the TOML in tml, either top level path = "url" pairs
+// or [[links]] tables as written by Export, and returns a MapHandler
+// serving them. Parse errors report the offending line.
+//
+//	"/nyt" = "https://www.nytimes.com"
+//
+//	[[links]]
+//	path = "nyt"
+//	url = "https://www.nytimes.com"
+//	tags = ["news"]
+func TOMLHandler(tml []byte, fallback http.Handler) (http.HandlerFunc, error) {
+	return decodeHandler(tml, FormatTOML, fallback)
+}
+
+// JSONHandler is YAMLHandler for JSON, accepting the flat ~/.map.json
+// object as well as a list of links.
+func JSONHandler(js []byte, fallback http.Handler) (http.HandlerFunc, error) {
+	return decodeHandler(js, FormatJSON, fallback)
+}
+
+func decodeHandler(b []byte, format string, fallback http.Handler) (http.HandlerFunc, error) {
+	links, err := Decode(bytes.NewReader(b), format)
+	if err != nil {
+		return nil, err
+	}
+	pathsToUrls := make(map[string]string, len(links))
+	for _, s := range links {
+		pathsToUrls[s.Path] = s.Site
+	}
+	return MapHandler(pathsToUrls, fallback), nil
+}
+
+// SetHandler returns a http.HandlerFunc capable of serving requests from the
+// links held in table. Link files are merged into the table with Table.Sync.
+// Links cannot be saved under the paths of the built-in pages, see
+// Options.Reserved.
+func SetHandler(table *Table, opts Options) http.HandlerFunc {
+	opts = opts.normalize()
+	table.Reserve(opts.Reserved())
+	for _, s := range table.All() {
+		if table.isReserved(s.Path) {
+			log.Printf("Link /%v is hidden by a built-in page, rename it", s.Path)
+		}
+	}
+	notFound := notFoundHandler(table, opts)
+	mux := defaultMux(table, opts)
+	pages := http.Handler(mux)
+	if opts.Prefix != "" {
+		pages = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r2, _ := stripPath(r, "/"+opts.Prefix)
+			if _, pattern := mux.Handler(r2); pattern == "/" {
+				// Report the path as it was asked for
+				notFound(w, r)
+				return
+			}
+			mux.ServeHTTP(w, r2)
+		})
+	}
+	return opts.mount(dbHandler(table, pages, notFound))
 }
 
-// DBHandler uses the database to lookup path keys
+// DBHandler uses the table snapshot of the database to lookup path keys.
+// Reserved paths go to the built-in pages first.
 
-func dbHandler(fallback http.Handler) http.HandlerFunc {
+func dbHandler(table *Table, pages, fallback http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		urlpath := strings.TrimLeft(r.URL.Path, "/")
 		log.Println(urlpath)
-		if path, ok := persist.Db.Get(urlpath); ok {
-			path.Count++
-			_ = persist.Db.Save(*path)
-			http.Redirect(w, r, path.Site, http.StatusFound)
+		if table.isReserved(urlpath) {
+			pages.ServeHTTP(w, r)
+			return
+		}
+		if path, target, ok := resolve(table.Lookup, table.rules(), r.URL); ok {
+			table.Visit(visitFrom(r, path.Path))
+			http.Redirect(w, r, target, http.StatusFound)
 			return
 		}
 		// Match paths in the map else
@@ -67,56 +138,151 @@ func dbHandler(fallback http.Handler) http.HandlerFunc {
 	}
 }
 
-func defaultMux() *http.ServeMux {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", msg)
-	mux.HandleFunc("/list", listHandler)
-	mux.HandleFunc("/static/", staticHandler)
-	return mux
+// route is a built-in page
+type route struct {
+	pattern string // below the page prefix, without the leading slash
+	h       http.HandlerFunc
 }
 
-func msg(w http.ResponseWriter, r *http.Request) {
-	http.NotFound(w, r)
-	log.Printf("Path not found: %v\n", r.URL.Path)
+// routes lists the built-in pages enabled by opts
+func routes(table *Table, opts Options) []route {
+	var rs []route
+	add := func(pattern string, h http.HandlerFunc) {
+		rs = append(rs, route{pattern, h})
+	}
+	if opts.enabled(FeatureList) {
+		add("list", listHandler(table, opts))
+		add("suggest", suggestHandler(table))
+	}
+	if opts.enabled(FeatureAPI) {
+		add(APIDoc[1:], apiDocHandler(opts))
+		add(APILinks[1:], apiHandler(table, opts))
+		add(APILinks[1:]+"/", apiHandler(table, opts))
+	}
+	if opts.enabled(FeatureShorten) {
+		add("shorten", shortenHandler(table, opts))
+	}
+	if opts.enabled(FeatureCreate) {
+		add("create", createHandler(table, opts))
+	}
+	if opts.enabled(FeatureStats) {
+		add("stats/", statsHandler(table))
+	}
+	if opts.enabled(FeatureExport) {
+		add("export", exportHandler(table))
+	}
+	if opts.enabled(FeatureImport) {
+		add("import", requireToken(opts.AdminToken, importHandler(table, opts)))
+	}
+	if opts.enabled(FeatureAdmin) {
+		add("admin/backup", requireToken(opts.AdminToken, backupHandler(table)))
+		add("admin/restore", requireToken(opts.AdminToken, restoreHandler(table)))
+		add("admin/conflicts", requireToken(opts.AdminToken, conflictsHandler(table, opts)))
+		add("admin/misses", requireToken(opts.AdminToken, missesHandler(table, opts)))
+		if opts.Reload != nil {
+			add("admin/reload", requireToken(opts.AdminToken, reloadHandler(opts.Reload)))
+		}
+	}
+	add("static/", staticHandler)
+	return rs
 }
 
-func listHandler(w http.ResponseWriter, r *http.Request) {
-	listHTML, err := getAsset("templates/list.gohtml")
-	if err != nil {
-		log.Println(err)
-		return
+func defaultMux(table *Table, opts Options) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", notFoundHandler(table, opts))
+	for _, rt := range routes(table, opts) {
+		mux.HandleFunc("/"+rt.pattern, rt.h)
 	}
-	listTemplate := template.Must(template.New("list").Parse(listHTML))
-	paths := getall()
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	render(w, r, listTemplate, "list", paths)
+	return mux
 }
 
-func getContent(file string) ([]byte, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
+// listEntry is a row of the list page
+type listEntry struct {
+	persist.Short
+	Source string
+	ETag   string // guards edits and deletes against changes made meanwhile
+}
 
-	c, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, err
-	}
-	return c, nil
+// Synced reports whether the link comes from a link file, which puts it
+// back on the next sync
+func (e listEntry) Synced() bool {
+	return e.Source != DBSource
 }
 
-func parseJSON(file string) (map[string]string, error) {
-	m := make(map[string]string)
-	jb, err := getContent(file)
-	if err != nil {
-		log.Panicf("Error getting map JSON: %v", err)
-	}
-	err = json.Unmarshal(jb, &m)
-	if err != nil {
-		return nil, err
+// listPage is what the list page shows
+type listPage struct {
+	Links   []listEntry
+	CanEdit bool
+	CSRF    string
+	Notice  string // left by the change that redirected here
+	Error   string
+	Add     linkForm   // values of the add form shown again
+	Edit    string     // path of the row being edited
+	Form    linkForm   // values of the edit form
+	Confirm *listEntry // link whose deletion awaits confirmation
+}
+
+// listHandler shows every link. Unless FeatureEdit is disabled, links can be
+// added, edited (?edit=path) and deleted from the page; changes redirect
+// back with a notice, and rejected ones show the form again with the reason.
+func listHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Changes need the admin token, as they do through the API
+		page := listPage{CanEdit: opts.enabled(FeatureEdit) && opts.AdminToken != ""}
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			page.Notice = takeFlash(w, r, opts)
+			if p := r.URL.Query().Get("edit"); p != "" && page.CanEdit {
+				if s, ok := table.Lookup(p); ok {
+					page.Edit, page.Form = p, formOf(s)
+				}
+			}
+		case http.MethodPost:
+			if !page.CanEdit {
+				http.Error(w, "editing links is disabled", http.StatusForbidden)
+				return
+			}
+			if status, msg := checkToken(opts.AdminToken, w, r); status != 0 {
+				http.Error(w, msg, status)
+				return
+			}
+			notice, err := listAction(table, r, &page)
+			if err != nil {
+				var e *APIError
+				if errors.Is(err, ErrGenerated) {
+					e = apiErrorf(http.StatusConflict, "%v", err)
+				} else if !errors.As(err, &e) {
+					e = apiErrorf(http.StatusBadRequest, "%v", err)
+				}
+				page.Error, status = e.Message, e.Status
+				break
+			}
+			if notice != "" {
+				setFlash(w, opts, notice)
+				http.Redirect(w, r, opts.page("list"), http.StatusSeeOther)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		// The import form needs the token too
+		page.CSRF = csrfToken(w, r, opts)
+		listTemplate, err := parseTemplate("list", opts)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "cannot show the page", http.StatusInternalServerError)
+			return
+		}
+		for _, s := range table.All() {
+			page.Links = append(page.Links, listEntry{s, table.Origin(s.Path), etag(s)})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		render(w, r, listTemplate, "list", page)
 	}
-	return m, nil
 }
 
 func render(w http.ResponseWriter, r *http.Request, tpl *template.Template, name string, data interface{}) {
@@ -128,6 +294,18 @@ func render(w http.ResponseWriter, r *http.Request, tpl *template.Template, name
 	w.Write(buf.Bytes())
 }
 
+// parseTemplate parses the embedded templates/<name>.gohtml. Templates
+// write the URLs of pages with {{page "list"}} and of links with
+// {{link .Path}}, so they keep working under a prefix and base path.
+func parseTemplate(name string, opts Options) (*template.Template, error) {
+	html, err := getAsset("templates/" + name + ".gohtml")
+	if err != nil {
+		return nil, err
+	}
+	funcs := template.FuncMap{"page": opts.page, "link": opts.link}
+	return template.New(name).Funcs(funcs).Parse(html)
+}
+
 func getAsset(asset string) (string, error) {
 	a, err := assets.Asset(asset)
 	if err != nil {
@@ -152,13 +330,26 @@ func staticHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(buf)
 }
 
-func getall() []persist.Short {
+func getall(store persist.Store) []persist.Short {
 	var ss []persist.Short
-	if s, ok := persist.Db.GetAll(); ok {
-		for _, v := range s {
-			ss = append(ss, v)
-			// pathUrls[v.Path] = v.Site
-		}
+	s, err := loadAll(store)
+	if err != nil {
+		log.Println(err)
+	}
+	for _, v := range s {
+		ss = append(ss, v)
+		// pathUrls[v.Path] = v.Site
 	}
 	return ss
 }
+
+// loadAll reads every link from store. Corrupt records are logged and left
+// out; any other error is returned.
+func loadAll(store persist.Store) (map[string]persist.Short, error) {
+	s, err := store.GetAll()
+	if errors.Is(err, persist.ErrCorrupt) {
+		log.Printf("Skipping unreadable links, run map fsck: %v", err)
+		return s, nil
+	}
+	return s, err
+}
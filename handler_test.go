@@ -0,0 +1,138 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"urlshort/persist"
+)
+
+// TestPagesEscape checks that the HTML pages escape what links and visitors
+// bring, so no one can plant a script in them
+func TestPagesEscape(t *testing.T) {
+	const script = `<script>alert(1)</script>`
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = table.Save(persist.Short{
+		Path:        "nyt",
+		Site:        `https://www.nytimes.com/?q="><script>alert(1)</script>`,
+		Description: script,
+		Tags:        []string{`"><img src=x onerror=alert(1)>`},
+		Creator:     script,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := SetHandler(table, Options{AdminToken: "s3"})
+	for _, p := range []string{"/list", "/list?edit=nyt", "/%3Cscript%3Ealert(1)%3C%2Fscript%3E"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		body := w.Body.String()
+		if !strings.Contains(body, "</html>") {
+			t.Errorf("GET %v = %v, want a page", p, w.Code)
+			continue
+		}
+		if strings.Contains(body, script) || strings.Contains(body, "<img") {
+			t.Errorf("GET %v left markup unescaped:\n%v", p, body)
+		}
+	}
+}
+
+// TestRulesLeavePages checks that a rule matching the path of a built-in
+// page can be saved and leaves the page alone
+func TestRulesLeavePages(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{}
+	table.Reserve(opts.Reserved())
+	if err := table.Save(persist.Short{Path: `^li(.*)$`, Site: "https://example.com/$1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Save(persist.Short{Path: `^.*$`, Site: "https://example.com/"}); err == nil {
+		t.Error("saved a rule matching /")
+	}
+	h := SetHandler(table, opts)
+	for p, want := range map[string]int{"/list": http.StatusOK, "/lisbon": http.StatusFound} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		if w.Code != want {
+			t.Errorf("GET %v = %v, want %v", p, w.Code, want)
+		}
+	}
+}
+
+// TestBasicAuthPosts checks that a post authenticated with the password a
+// browser keeps must come from one of the server's forms
+func TestBasicAuthPosts(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloads := 0
+	h := SetHandler(table, Options{AdminToken: "s3", Reload: func() ReloadResult {
+		reloads++
+		return ReloadResult{OK: true}
+	}})
+	for _, tt := range []struct {
+		name string
+		auth func(r *http.Request)
+		csrf bool
+		want int
+	}{
+		{"basic", func(r *http.Request) { r.SetBasicAuth("", "s3") }, false, http.StatusForbidden},
+		{"basic with form token", func(r *http.Request) { r.SetBasicAuth("", "s3") }, true, http.StatusOK},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3") }, false, http.StatusOK},
+	} {
+		body := ""
+		if tt.csrf {
+			body = "csrf=t0k"
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/reload", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: csrfCookie, Value: "t0k"})
+		tt.auth(r)
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%v: POST /admin/reload = %v, want %v", tt.name, w.Code, tt.want)
+		}
+	}
+	if reloads != 2 {
+		t.Errorf("reloaded %v times, want 2", reloads)
+	}
+}
+
+// TestListAddCreator checks that a link added from the list page is
+// credited to the name given, or else to the user who logged in
+func TestListAddCreator(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := SetHandler(table, Options{AdminToken: "s3"})
+	for _, tt := range []struct{ path, creator, want string }{
+		{"gh", "ana", "ana"},
+		{"go", "", "bo"},
+	} {
+		form := url.Values{"action": {"add"}, "path": {tt.path}, "url": {"https://example.com"}, "creator": {tt.creator}, "csrf": {"t0k"}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/list", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: csrfCookie, Value: "t0k"})
+		r.SetBasicAuth("bo", "s3")
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("add /%v = %v %s", tt.path, w.Code, w.Body)
+		}
+		if s, _ := table.Lookup(tt.path); s.Creator != tt.want {
+			t.Errorf("/%v created by %q, want %q", tt.path, s.Creator, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"urlshort"
+)
+
+// backupPrefix and backupExt name the files written by autoBackup
+const (
+	backupPrefix = "map-"
+	backupExt    = ".bak"
+)
+
+func backupCmd(args []string) error {
+	fs := cmdFlags("backup")
+	server := fs.String("server", "", "base URL of a running server to back up instead of opening the store")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("backup needs a file name")
+	}
+	return writeFile(fs.Arg(0), func(w io.Writer) error {
+		if *server != "" {
+			return remoteBackup(*server, w)
+		}
+		store, err := commandStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		return store.Backup(w)
+	})
+}
+
+func restoreCmd(args []string) error {
+	fs := cmdFlags("restore")
+	server := fs.String("server", "", "base URL of a running server to restore into instead of opening the store")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("restore needs a file name")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if *server != "" {
+		return remoteRestore(*server, f)
+	}
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.Restore(f)
+}
+
+// adminRequest sends an authenticated request to the admin page of the
+// server at base, which includes any base path, found under the -prefix
+func adminRequest(method, base, page string, body io.Reader) (*http.Response, error) {
+	path := "/" + page
+	if *pagePrefix != "" {
+		path = "/" + strings.Trim(*pagePrefix, "/") + path
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(base, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+*adminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v %v: %v %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func remoteBackup(base string, w io.Writer) error {
+	resp, err := adminRequest(http.MethodGet, base, "admin/backup", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func remoteRestore(base string, r io.Reader) error {
+	resp, err := adminRequest(http.MethodPost, base, "admin/restore", r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(os.Stdout, resp.Body)
+	return err
+}
+
+// writeFile writes name through a temporary file so a failed write never
+// leaves a truncated file behind
+func writeFile(name string, write func(io.Writer) error) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// autoBackup writes a backup of the store of table into dir every interval,
+// keeping the newest keep files, until stop is closed. Pending visits are
+// flushed first so the backup counts them.
+func autoBackup(table *urlshort.Table, dir string, every time.Duration, keep int, stop <-chan struct{}) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Printf("Automatic backups disabled: %v", err)
+		return
+	}
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			if err := table.Flush(); err != nil {
+				log.Printf("Backing up without some visit counts: %v", err)
+			}
+			name := filepath.Join(dir, backupPrefix+time.Now().Format("20060102T150405")+backupExt)
+			if err := writeFile(name, table.Store().Backup); err != nil {
+				log.Printf("Automatic backup failed: %v", err)
+				continue
+			}
+			if err := pruneBackups(dir, keep); err != nil {
+				log.Printf("Failed to remove old backups: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// pruneBackups removes all but the newest keep backups in dir. Backup names
+// sort by the time they were taken.
+func pruneBackups(dir string, keep int) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), backupPrefix) && strings.HasSuffix(f.Name(), backupExt) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	"urlshort"
+	"urlshort/persist"
+)
+
+// command is a subcommand run instead of the server
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"add":     {"add [-desc text] [-tags a,b] [-creator name] [-path mode] [-query mode] [-fragment f] [-params p] path url", addCmd},
+		"fsck":    {"fsck [-quarantine]", fsckCmd},
+		"backup":  {"backup [-server url] file", backupCmd},
+		"restore": {"restore [-server url] file", restoreCmd},
+		"export":  {"export [-format f] [file]", exportCmd},
+		"import":  {"import [-format f] [-conflict skip|overwrite|rename] [-dry-run] file", importCmd},
+		"diff":    {"diff [-sync policy] [file ...]", diffCmd},
+		"shorten": {"shorten url", shortenCmd},
+	}
+}
+
+// runCommand runs the subcommand named by args[0]
+func runCommand(args []string) error {
+	c, ok := commands[args[0]]
+	if !ok {
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return c.run(args[1:])
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: map [flags] [command]\n\nCommands:\n")
+	var names []string
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  map %v\n", commands[n].usage)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// commandStore opens the store selected by the global flags for a subcommand
+func commandStore() (persist.Store, error) {
+	return openStore(*backend, *dataDir, *ephemeral)
+}
+
+// openTable loads the links in store, keeping the paths of the built-in
+// pages free as the server would
+func openTable(store persist.Store) (*urlshort.Table, error) {
+	table, err := urlshort.NewTable(store)
+	if err != nil {
+		return nil, err
+	}
+	table.Reserve(pageOptions().Reserved())
+	return table, nil
+}
+
+// cmdFlags returns a flag set for subcommand name that prints its usage line
+func cmdFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: map %v\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func addCmd(args []string) error {
+	fs := cmdFlags("add")
+	desc := fs.String("desc", "", "free text description")
+	tags := fs.String("tags", "", "comma separated tags")
+	creator := fs.String("creator", currentUser(), "who created the link")
+	pathMode := fs.String("path", "", "extra path after the link: append (default), drop or exact")
+	queryMode := fs.String("query", "", "request query: merge (default), replace or drop")
+	fragment := fs.String("fragment", "", "fragment to set on the target")
+	params := fs.String("params", "", "query parameters added to every redirect, e.g. utm_source=map")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("add needs a path and a url")
+	}
+	s := persist.Short{
+		Path:        strings.TrimLeft(fs.Arg(0), "/"),
+		Site:        fs.Arg(1),
+		Creator:     *creator,
+		Description: *desc,
+		Tags:        splitTags(*tags),
+		Forward:     persist.Forward{Path: *pathMode, Query: *queryMode, Fragment: *fragment, Params: *params},
+	}
+	if u, err := url.Parse(s.Site); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute url", s.Site)
+	}
+	if err := s.Forward.Check(); err != nil {
+		return err
+	}
+
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	table, err := openTable(store)
+	if err != nil {
+		return err
+	}
+	return table.Save(s)
+}
+
+func shortenCmd(args []string) error {
+	fs := cmdFlags("shorten")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("shorten needs a url")
+	}
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	table, err := openTable(store)
+	if err != nil {
+		return err
+	}
+	s, _, err := table.Shorten(fs.Arg(0), codeOptions())
+	if err != nil {
+		return err
+	}
+	fmt.Println(s.Path)
+	return nil
+}
+
+// splitTags turns a comma separated list into trimmed, non-empty tags
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func fsckCmd(args []string) error {
+	fs := cmdFlags("fsck")
+	quarantine := fs.Bool("quarantine", false, "move problem records out of the link table")
+	fs.Parse(args)
+
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	problems, err := store.Check()
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return nil
+	}
+	keys := make(map[string]bool)
+	for _, p := range problems {
+		fmt.Println(p)
+		keys[p.Key] = true
+	}
+	if !*quarantine {
+		return fmt.Errorf("%v problems found in %v records, rerun with -quarantine to isolate them", len(problems), len(keys))
+	}
+	var qk []string
+	for k := range keys {
+		qk = append(qk, k)
+	}
+	if err := store.Quarantine(qk); err != nil {
+		return err
+	}
+	fmt.Printf("Quarantined %v records\n", len(qk))
+	return nil
+}
+
+func exportCmd(args []string) error {
+	fs := cmdFlags("export")
+	format := fs.String("format", "", "one of "+strings.Join(urlshort.Formats, ", ")+", guessed from the file name when empty")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errors.New("export takes at most one file name")
+	}
+
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	table, err := openTable(store)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		if *format == "" {
+			*format = urlshort.FormatJSON
+		}
+		return urlshort.Export(os.Stdout, *format, table.All())
+	}
+	if *format == "" {
+		*format = urlshort.FormatOf(fs.Arg(0))
+	}
+	return writeFile(fs.Arg(0), func(w io.Writer) error {
+		return urlshort.Export(w, *format, table.All())
+	})
+}
+
+func importCmd(args []string) error {
+	fs := cmdFlags("import")
+	format := fs.String("format", "", "one of "+strings.Join(urlshort.Formats, ", ")+", guessed from the file name when empty")
+	conflict := fs.String("conflict", urlshort.ConflictSkip, "what to do with paths that exist: skip, overwrite or rename")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("import needs a file name")
+	}
+	if *format == "" {
+		*format = urlshort.FormatOf(fs.Arg(0))
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	links, err := urlshort.Decode(f, *format)
+	if err != nil {
+		return fmt.Errorf("%v: %v", fs.Arg(0), err)
+	}
+
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	table, err := openTable(store)
+	if err != nil {
+		return err
+	}
+	res, err := table.Import(links, *conflict, *dryRun)
+	if err != nil {
+		return err
+	}
+	for from, to := range res.Renamed {
+		fmt.Printf("%v -> %v\n", from, to)
+	}
+	if len(res.Skipped) > 0 {
+		fmt.Printf("Skipped: %v\n", strings.Join(res.Skipped, ", "))
+	}
+	if len(res.Reserved) > 0 {
+		fmt.Printf("Reserved: %v\n", strings.Join(res.Reserved, ", "))
+	}
+	fmt.Println(res)
+	return nil
+}
+
+func diffCmd(args []string) error {
+	fs := cmdFlags("diff")
+	policy := fs.String("sync", *syncMode, "merge policy to compare with: "+strings.Join(urlshort.SyncPolicies, ", "))
+	fs.Parse(args)
+	sources, err := linkSources()
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		sources = nil
+		for i, file := range fs.Args() {
+			sources = append(sources, urlshort.Source{Name: file, File: file, Priority: i})
+		}
+	}
+	if len(sources) == 0 {
+		fs.Usage()
+		return errors.New("diff needs a link file")
+	}
+
+	store, err := commandStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	table, err := openTable(store)
+	if err != nil {
+		return err
+	}
+	changes, err := table.SyncSources(sources, *policy, true)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	return nil
+}
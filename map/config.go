@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"urlshort"
+	"urlshort/persist"
+
+	"gopkg.in/yaml.v3"
+)
+
+var configFile = flag.String("config", filepath.Join(defaultDataDir(), "config.yaml"), "server configuration file, reloaded on SIGHUP and SIGUSR1")
+
+// config is the layout of the server configuration file. Every setting
+// mirrors a command line flag, and flags given on the command line win.
+type config struct {
+	Listen   []string `yaml:"listen"`
+	Timeouts struct {
+		Read  string `yaml:"read"`
+		Write string `yaml:"write"`
+		Idle  string `yaml:"idle"`
+	} `yaml:"timeouts"`
+	Store     string `yaml:"store"`
+	DataDir   string `yaml:"data_dir"`
+	Ephemeral string `yaml:"ephemeral"`
+	Flush     string `yaml:"flush"`
+	TLS       struct {
+		Cert string `yaml:"cert"`
+		Key  string `yaml:"key"`
+	} `yaml:"tls"`
+	Auth struct {
+		AdminToken string `yaml:"admin_token"`
+	} `yaml:"auth"`
+	Backup struct {
+		Dir   string `yaml:"dir"`
+		Every string `yaml:"every"`
+		Keep  string `yaml:"keep"`
+	} `yaml:"backup"`
+	Links struct {
+		Map        string `yaml:"map"`
+		Team       string `yaml:"team"`
+		ConfDir    string `yaml:"conf_dir"`
+		Priorities string `yaml:"priorities"`
+		Sync       string `yaml:"sync"`
+	} `yaml:"links"`
+	Codes struct {
+		Length    string `yaml:"length"`
+		Obfuscate string `yaml:"obfuscate"`
+	} `yaml:"codes"`
+	Pages struct {
+		Prefix   string `yaml:"prefix"`
+		BasePath string `yaml:"base_path"`
+	} `yaml:"pages"`
+	Features map[string]bool `yaml:"features"`
+}
+
+// configFlags lists the flags the configuration file can set
+var configFlags = []string{
+	"listen", "read-timeout", "write-timeout", "idle-timeout",
+	"store", "data-dir", "ephemeral", "flush",
+	"tls-cert", "tls-key", "admin-token",
+	"backup-dir", "backup-every", "backup-keep",
+	"map", "team", "conf-dir", "priorities", "sync", "disable", "enable",
+	"code-length", "obfuscate-codes", "prefix", "base-path",
+}
+
+// restartFlags lists the settings that only take effect on a restart
+var restartFlags = map[string]bool{
+	"listen": true, "p": true, "read-timeout": true, "write-timeout": true, "idle-timeout": true,
+	"store": true, "data-dir": true, "ephemeral": true, "flush": true,
+}
+
+// readConfig reads the configuration file. A missing file is an empty
+// configuration.
+func readConfig(file string) (*config, error) {
+	c := &config{}
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%v: %v", file, err)
+	}
+	for name := range c.Features {
+		if !isFeature(name) {
+			return nil, fmt.Errorf("%v: unknown feature %q", file, name)
+		}
+	}
+	return c, nil
+}
+
+// values returns the flag values set by the configuration
+func (c *config) values() map[string]string {
+	v := make(map[string]string)
+	set := func(name, value string, path bool) {
+		if value == "" {
+			return
+		}
+		if path {
+			value = expandHome(value)
+		}
+		v[name] = value
+	}
+	set("listen", strings.Join(c.Listen, ","), false)
+	set("read-timeout", c.Timeouts.Read, false)
+	set("write-timeout", c.Timeouts.Write, false)
+	set("idle-timeout", c.Timeouts.Idle, false)
+	set("store", c.Store, false)
+	set("data-dir", c.DataDir, true)
+	set("ephemeral", c.Ephemeral, false)
+	set("flush", c.Flush, false)
+	set("tls-cert", c.TLS.Cert, true)
+	set("tls-key", c.TLS.Key, true)
+	set("admin-token", c.Auth.AdminToken, false)
+	set("backup-dir", c.Backup.Dir, true)
+	set("backup-every", c.Backup.Every, false)
+	set("backup-keep", c.Backup.Keep, false)
+	set("map", c.Links.Map, true)
+	set("team", c.Links.Team, true)
+	set("conf-dir", c.Links.ConfDir, true)
+	set("priorities", c.Links.Priorities, false)
+	set("sync", c.Links.Sync, false)
+	set("code-length", c.Codes.Length, false)
+	set("obfuscate-codes", c.Codes.Obfuscate, false)
+	set("prefix", c.Pages.Prefix, false)
+	set("base-path", c.Pages.BasePath, false)
+	var off, on []string
+	for name, enabled := range c.Features {
+		if enabled {
+			on = append(on, name)
+		} else {
+			off = append(off, name)
+		}
+	}
+	sort.Strings(off)
+	sort.Strings(on)
+	set("disable", strings.Join(off, ","), false)
+	set("enable", strings.Join(on, ","), false)
+	return v
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+// explicitFlags returns the flags given on the command line
+func explicitFlags() map[string]bool {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// applyConfig sets the flags from the configuration file, leaving alone
+// the ones in explicit. Settings removed from the file go back to their
+// defaults. It returns the names of the flags whose value changed and their
+// previous values, for restore when a later step turns the change down; on
+// error no flag is changed.
+func applyConfig(file string, explicit map[string]bool) ([]string, map[string]string, error) {
+	c, err := readConfig(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := c.values()
+	old := make(map[string]string)
+	var changed []string
+	for _, name := range configFlags {
+		if explicit[name] {
+			continue
+		}
+		f := flag.Lookup(name)
+		v, ok := values[name]
+		if !ok {
+			v = f.DefValue
+		}
+		old[name] = f.Value.String()
+		if err := f.Value.Set(v); err != nil {
+			restore(old)
+			return nil, nil, fmt.Errorf("%v: bad %v %q: %v", file, name, v, err)
+		}
+		if f.Value.String() != old[name] {
+			changed = append(changed, name)
+		}
+	}
+	if err := checkSettings(); err != nil {
+		restore(old)
+		return nil, nil, fmt.Errorf("%v: %v", file, err)
+	}
+	return changed, old, nil
+}
+
+// restore puts back the flag values returned by applyConfig
+func restore(old map[string]string) {
+	for name, v := range old {
+		flag.Set(name, v)
+	}
+}
+
+// checkSettings validates the flags that take a fixed set or range of values
+func checkSettings() error {
+	if !contains(persist.Backends, *backend) {
+		return fmt.Errorf("unknown store %q", *backend)
+	}
+	if !contains(urlshort.SyncPolicies, *syncMode) {
+		return fmt.Errorf("unknown sync policy %q", *syncMode)
+	}
+	if *flush <= 0 {
+		return fmt.Errorf("flush must be positive, not %v", *flush)
+	}
+	if *backupEvery <= 0 {
+		return fmt.Errorf("backup-every must be positive, not %v", *backupEvery)
+	}
+	if *backupKeep < 1 {
+		return fmt.Errorf("backup-keep must be at least 1, not %v", *backupKeep)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return errors.New("tls-cert and tls-key go together")
+	}
+	if err := pageOptions().Check(); err != nil {
+		return err
+	}
+	_, err := linkSources()
+	return err
+}
+
+// codeOptions returns the settings of generated short codes
+func codeOptions() urlshort.CodeOptions {
+	return urlshort.CodeOptions{Length: *codeLength, Obfuscate: *obfuscateCodes}
+}
+
+// pageOptions returns the settings of the built-in pages, less the reload
+// hook only the running server has
+func pageOptions() urlshort.Options {
+	return urlshort.Options{
+		AdminToken: *adminToken,
+		Disable:    featureList(*disable),
+		Enable:     featureList(*enable),
+		Codes:      codeOptions(),
+		Prefix:     *pagePrefix,
+		BasePath:   *basePath,
+	}
+}
+
+// featureList splits a comma separated list of features
+func featureList(s string) []string {
+	var list []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			list = append(list, f)
+		}
+	}
+	return list
+}
+
+func isFeature(name string) bool {
+	return contains(urlshort.Features, name)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
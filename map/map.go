@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -19,50 +24,342 @@ import (
 )
 
 var (
-	mapFile string
-	port    = flag.Int("p", 8080, "listening port")
+	mapFile   = flag.String("map", defaultMapFile(), "personal link file synced into the store, empty to disable")
+	syncMode  = flag.String("sync", urlshort.SyncUnion, "how link files are merged into the store: "+strings.Join(urlshort.SyncPolicies, ", "))
+	port      = flag.Int("p", 8080, "listening port on localhost, unless -listen is given")
+	listen    = flag.String("listen", "", "comma separated addresses to listen on, e.g. :8080,[::1]:8081")
+	backend   = flag.String("store", "badger", "storage backend: "+strings.Join(persist.Backends, ", "))
+	dataDir   = flag.String("data-dir", defaultDataDir(), "directory holding the link database")
+	ephemeral = flag.Bool("ephemeral", false, "keep links in memory only and discard them on exit")
+	flush     = flag.Duration("flush", 10*time.Second, "interval between writes of visit counts to the store")
+
+	readTimeout  = flag.Duration("read-timeout", 120*time.Second, "maximum duration for reading a request")
+	writeTimeout = flag.Duration("write-timeout", 120*time.Second, "maximum duration for writing a response")
+	idleTimeout  = flag.Duration("idle-timeout", 120*time.Second, "how long idle keep-alive connections are kept open")
+	tlsCert      = flag.String("tls-cert", "", "certificate file, serve HTTPS when set together with -tls-key")
+	tlsKey       = flag.String("tls-key", "", "private key file for -tls-cert")
+	disable      = flag.String("disable", "", "comma separated features to switch off: "+strings.Join(urlshort.Features, ", "))
+	enable       = flag.String("enable", "", "comma separated features to switch on: "+strings.Join(urlshort.OptIn, ", "))
+
+	adminToken  = flag.String("admin-token", os.Getenv("MAP_ADMIN_TOKEN"), "bearer token for the /admin/ endpoints, defaults to $MAP_ADMIN_TOKEN")
+	backupDir   = flag.String("backup-dir", "", "directory for automatic backups, disabled when empty")
+	backupEvery = flag.Duration("backup-every", 24*time.Hour, "interval between automatic backups")
+	backupKeep  = flag.Int("backup-keep", 7, "number of automatic backups to keep")
+
+	codeLength     = flag.Int("code-length", urlshort.DefaultCodeLength, "least number of characters in generated short codes")
+	obfuscateCodes = flag.Bool("obfuscate-codes", false, "scramble generated short codes so they cannot be guessed in order")
+
+	pagePrefix = flag.String("prefix", "", "path segment to serve the built-in pages under, e.g. _ for /_/list, leaving every other path to links")
+	basePath   = flag.String("base-path", "", "path a reverse proxy serves map under, e.g. /go")
 )
 
 func main() {
 
+	flag.Usage = usage
 	flag.Parse()
+	explicit := explicitFlags()
+	if _, _, err := applyConfig(*configFile, explicit); err != nil {
+		log.Fatal(err)
+	}
+	if flag.NArg() > 0 {
+		if err := runCommand(flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	persist.Db.Open()
-	defer persist.Db.DB.Close()
+	store, err := openStore(*backend, *dataDir, *ephemeral)
+	if err != nil {
+		log.Fatalf("Failed to open %v store: %v", *backend, err)
+	}
+	defer store.Close()
+	if m, ok := store.(persist.Migrator); ok {
+		go func() {
+			n, err := m.Migrate()
+			if err != nil {
+				log.Printf("Failed to migrate records: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Migrated %v records to the current format", n)
+			}
+		}()
+	}
 
-	handler := urlshort.SetHandler(mapFile)
-	addr := fmt.Sprintf("localhost:%v", *port)
+	table, err := urlshort.NewTable(store)
+	if err != nil {
+		log.Fatalf("Failed to load links: %v", err)
+	}
+	table.Run(*flush)
+	defer table.Close()
+
+	s := &server{table: table, explicit: explicit}
+	table.Reserve(s.options().Reserved())
+	s.startBackups()
+	defer s.stopBackups()
+
+	if s.sources, err = linkSources(); err != nil {
+		log.Fatal(err)
+	}
+	syncSources(table, s.sources, *syncMode)
+
+	//Set up a file watcher
+	s.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.watcher.Close()
+	s.isSource = watchSources(s.watcher, s.sources)
+
+	handler, err := urlshort.NewHandler(table, s.options())
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.root = urlshort.NewRoot(handler)
 
 	// Start server
-	srv := &http.Server{
-		Addr:         addr,
-		ReadTimeout:  120 * time.Second,
-		WriteTimeout: 120 * time.Second,
-		IdleTimeout:  120 * time.Second,
-		Handler:      handler,
+	s.srv = &http.Server{
+		ReadTimeout:  *readTimeout,
+		WriteTimeout: *writeTimeout,
+		IdleTimeout:  *idleTimeout,
+		Handler:      s.root,
+	}
+	if s.tls = *tlsCert != "" || *tlsKey != ""; s.tls {
+		if err := s.loadCertificate(); err != nil {
+			log.Fatal(err)
+		}
+		s.srv.TLSConfig = &tls.Config{GetCertificate: s.certificate}
+	}
+	for _, addr := range listenAddrs() {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go startServer(s.srv, ln, s.tls)
 	}
-	go startServer(srv)
 
 	//wait for signal
-	err := signalWait(srv, mapFile)
+	err = signalWait(s)
 	if err != nil {
 		log.Fatalf("Failed to shutdown server %v", err)
 	}
 }
 
-func init() {
-	// READ json map from ~/.map/map.json
+// defaultMapFile returns the first of ~/.map.json, ~/.map.yaml, ~/.map.yml
+// and ~/.map.toml that exists, ~/.map.json when none does, or nothing when
+// there is no home directory
+func defaultMapFile() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		return ""
+	}
+	for _, ext := range []string{".json", ".yaml", ".yml", ".toml"} {
+		file := filepath.Join(home, ".map"+ext)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
 	}
-	mapFile = filepath.Join(home, ".map.json")
+	return filepath.Join(home, ".map.json")
 }
 
-func startServer(srv *http.Server) {
-	log.Printf("Starting the server on localhost:%+v", *port)
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Printf("HTTP ListenAndServe: %v", err)
+// listenAddrs returns the addresses to listen on
+func listenAddrs() []string {
+	var addrs []string
+	for _, a := range strings.Split(*listen, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("localhost:%v", *port)}
+	}
+	return addrs
+}
+
+// server holds the parts of the running server a reload can replace
+type server struct {
+	srv   *http.Server
+	root  *urlshort.Root
+	table *urlshort.Table
+
+	// mu serialises reloads from signals, file changes and /admin/reload
+	mu       sync.Mutex
+	watcher  *fsnotify.Watcher
+	sources  []urlshort.Source
+	isSource func(name string) bool
+	explicit map[string]bool // flags given on the command line
+
+	tls  bool
+	cert atomic.Value // *tls.Certificate
+
+	backupStop chan struct{}
+}
+
+func (s *server) options() urlshort.Options {
+	opts := pageOptions()
+	opts.Reload = s.reload
+	return opts
+}
+
+// rebuild swaps in a handler built from the current settings, keeping the
+// old one if that fails
+func (s *server) rebuild() error {
+	return s.root.Reload(func() (http.Handler, error) {
+		return urlshort.NewHandler(s.table, s.options())
+	})
+}
+
+func (s *server) certificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.cert.Load().(*tls.Certificate), nil
+}
+
+// loadCertificate reads the TLS key pair, keeping the current one on error
+func (s *server) loadCertificate() error {
+	cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	s.cert.Store(&cert)
+	return nil
+}
+
+func (s *server) startBackups() {
+	if *backupDir == "" {
+		return
+	}
+	s.backupStop = make(chan struct{})
+	go autoBackup(s.table, *backupDir, *backupEvery, *backupKeep, s.backupStop)
+}
+
+func (s *server) stopBackups() {
+	if s.backupStop != nil {
+		close(s.backupStop)
+		s.backupStop = nil
+	}
+}
+
+// reload rereads the configuration file, applies what changed, syncs the
+// link files and swaps in a new handler. The new settings, sources and links
+// are all checked before any of them is put to use, so on failure the old
+// configuration, links and handler stay as they were. Settings that only
+// take effect on a restart are reported and otherwise ignored until then.
+func (s *server) reload() urlshort.ReloadResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := urlshort.ReloadResult{}
+	fail := func(what string, err error) urlshort.ReloadResult {
+		log.Printf("%v: %v", what, err)
+		res.Error = fmt.Sprintf("%v: %v", what, err)
+		res.Links = s.table.Len()
+		return res
+	}
+
+	changed, old, err := applyConfig(*configFile, s.explicit)
+	if err != nil {
+		return fail("Keeping the current configuration", err)
+	}
+	sources := s.sources
+	for _, name := range changed {
+		if name == "map" || name == "team" || name == "conf-dir" || name == "priorities" {
+			if sources, err = linkSources(); err != nil {
+				restore(old)
+				return fail("Keeping the current configuration", err)
+			}
+			break
+		}
+	}
+	opts := s.options()
+	plan, err := planSync(s.table, sources, *syncMode, opts.Reserved())
+	if err != nil {
+		restore(old)
+		return fail("Keeping the current configuration and links", err)
+	}
+
+	// Everything checks out, put it to use. undo goes back to the previous
+	// settings should the links not take.
+	res.Changed = changed
+	backups, certs := false, false
+	oldSources := s.sources
+	undo := func() {
+		restore(old)
+		res.Changed, res.Restart = nil, nil
+		if backups {
+			s.stopBackups()
+			s.startBackups()
+		}
+		if certs {
+			if err := s.loadCertificate(); err != nil {
+				log.Printf("Keeping the new certificate: %v", err)
+			}
+		}
+		if !sameSources(oldSources, s.sources) {
+			s.sources = oldSources
+			s.isSource = watchSources(s.watcher, oldSources)
+		}
+		s.table.Reserve(s.options().Reserved())
+	}
+	var restart []string
+	for _, name := range changed {
+		switch {
+		case restartFlags[name]:
+			restart = append(restart, name)
+		case name == "tls-cert" || name == "tls-key":
+			if s.tls != (*tlsCert != "" || *tlsKey != "") {
+				restart = append(restart, name)
+			} else if s.tls {
+				certs = true
+				if err := s.loadCertificate(); err != nil {
+					log.Printf("Keeping the current certificate: %v", err)
+				}
+			}
+		case strings.HasPrefix(name, "backup-"):
+			backups = true
+			s.stopBackups()
+			s.startBackups()
+		}
+	}
+	if len(changed) > 0 {
+		log.Printf("Configuration changed: %v", strings.Join(changed, ", "))
+	}
+	if len(restart) > 0 {
+		log.Printf("Restart to apply: %v", strings.Join(restart, ", "))
+	}
+	res.Restart = restart
+	if !sameSources(sources, s.sources) {
+		s.sources = sources
+		s.isSource = watchSources(s.watcher, sources)
+	}
+
+	s.table.Reserve(opts.Reserved())
+	if res.Synced, err = applySync(s.table, plan, *syncMode); err != nil {
+		undo()
+		return fail("Failed to sync links", err)
+	}
+	if err := s.rebuild(); err != nil {
+		undo()
+		return fail("Keeping the current handler and configuration", err)
+	}
+	res.OK = true
+	res.Links = s.table.Len()
+	return res
+}
+
+// resync syncs the link files after one of them changed
+func (s *server) resync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	syncSources(s.table, s.sources, *syncMode)
+}
+
+func startServer(srv *http.Server, ln net.Listener, useTLS bool) {
+	log.Printf("Starting the server on %v", ln.Addr())
+	var err error
+	if useTLS {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+	if err != http.ErrServerClosed {
+		log.Printf("HTTP Serve: %v", err)
 	}
 }
 
@@ -73,45 +370,33 @@ func closeServer(srv *http.Server) error {
 	return srv.Shutdown(ctx)
 }
 
-func signalWait(srv *http.Server, mfile string) error {
+func signalWait(s *server) error {
 	// Handle signals
 	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1)
-	//Set up a file watcher
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Fatal(err)
-		return err
-	}
-	defer watcher.Close()
-
-	err = watcher.Add(mfile)
-	if err != nil {
-		log.Fatal(err)
-		return err
-	}
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGHUP)
 
 	for {
 		select {
 		case sig := <-sigs:
 			switch sig {
-			case syscall.SIGUSR1:
-				log.Println("User 1 signal received. Reloading config...")
-				handler := urlshort.SetHandler(mfile)
-				srv.Handler = handler
+			case syscall.SIGUSR1, syscall.SIGHUP:
+				log.Printf("%v received. Reloading config...", sig)
+				s.reload()
 			case os.Interrupt, syscall.SIGTERM:
-				return closeServer(srv)
+				return closeServer(s.srv)
 			}
-		case event, ok := <-watcher.Events:
+		case event, ok := <-s.watcher.Events:
 			if !ok {
 				break
 			}
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				log.Println("Config file changed. Reloading config...")
-				handler := urlshort.SetHandler(mfile)
-				srv.Handler = handler
+			s.mu.Lock()
+			isSource := s.isSource(event.Name)
+			s.mu.Unlock()
+			if isSource && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+				log.Printf("Link file %v changed. Syncing links...", event.Name)
+				s.resync()
 			}
-		case err, ok := <-watcher.Errors:
+		case err, ok := <-s.watcher.Errors:
 			if !ok {
 				return err
 			}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"urlshort"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var (
+	teamFile   = flag.String("team", "", "shared link file, e.g. in a team checkout")
+	confDir    = flag.String("conf-dir", "", "directory of link files, conf.d in the data directory by default")
+	priorities = flag.String("priorities", "personal=30,team=20,conf.d=10", "priority of each link source, the highest wins; db ranks links changed in the store")
+)
+
+// linkSources lists the configured link sources with their priorities
+func linkSources() ([]urlshort.Source, error) {
+	prio := make(map[string]int)
+	for _, kv := range strings.Split(*priorities, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("bad priority %q, want name=number", kv)
+		}
+		n, err := strconv.Atoi(kv[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("bad priority %q: %v", kv, err)
+		}
+		switch name := kv[:i]; name {
+		case "personal", "team", "conf.d", urlshort.DBSource:
+			prio[name] = n
+		default:
+			return nil, fmt.Errorf("unknown link source %q in priorities", name)
+		}
+	}
+
+	// The data directory may come from the command line or the
+	// configuration file, so the default is only worked out now
+	dir := *confDir
+	if dir == "" {
+		dir = filepath.Join(*dataDir, "conf.d")
+	}
+	var sources []urlshort.Source
+	for _, src := range []urlshort.Source{
+		{Name: "personal", File: *mapFile},
+		{Name: "team", File: *teamFile},
+		{Name: "conf.d", File: dir},
+	} {
+		if src.File == "" {
+			continue
+		}
+		src.File = filepath.Clean(src.File)
+		src.Priority = prio[src.Name]
+		sources = append(sources, src)
+	}
+	if n, ok := prio[urlshort.DBSource]; ok {
+		sources = append(sources, urlshort.Source{Name: urlshort.DBSource, Priority: n})
+	}
+	return sources, nil
+}
+
+// syncSources merges the link sources into the table, logs what changed and
+// returns the number of changes. Missing sources are not an error: the store
+// is simply used as it is.
+func syncSources(table *urlshort.Table, sources []urlshort.Source, policy string) (int, error) {
+	plan, err := planSync(table, sources, policy, nil)
+	if err != nil {
+		log.Printf("Failed to sync links: %v", err)
+		return 0, err
+	}
+	return applySync(table, plan, policy)
+}
+
+// planSync is the checking half of syncSources, reserved naming the paths
+// to keep out, nil for the ones the table already keeps out. The plan is nil
+// when there is nothing to sync.
+func planSync(table *urlshort.Table, sources []urlshort.Source, policy string, reserved []string) (*urlshort.SyncPlan, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	if reserved == nil {
+		reserved = table.Reserved()
+	}
+	plan, err := table.PlanSync(sources, policy, reserved)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No link files found, skipping sync")
+		return nil, nil
+	}
+	return plan, err
+}
+
+// applySync writes a plan made by planSync under policy and logs the changes
+func applySync(table *urlshort.Table, plan *urlshort.SyncPlan, policy string) (int, error) {
+	if plan == nil {
+		return 0, nil
+	}
+	if err := table.ApplySync(plan); err != nil {
+		log.Printf("Failed to sync links: %v", err)
+		return 0, err
+	}
+	for _, c := range plan.Changes {
+		log.Printf("Sync %v", c)
+	}
+	log.Printf("Synced links with %v changes (policy %v)", len(plan.Changes), policy)
+	return len(plan.Changes), nil
+}
+
+// sameSources reports whether a and b list the same sources
+func sameSources(a, b []urlshort.Source) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// watchSources adds the link sources to watcher and returns a function
+// telling whether a changed file belongs to one of them. Directories holding
+// the files are watched so files replaced on save or created later are seen.
+func watchSources(watcher *fsnotify.Watcher, sources []urlshort.Source) func(name string) bool {
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, src := range sources {
+		if src.File == "" {
+			// The store
+			continue
+		}
+		dir := filepath.Dir(src.File)
+		if fi, err := os.Stat(src.File); err == nil && fi.IsDir() {
+			dirs[src.File] = true
+			dir = src.File
+		} else {
+			files[src.File] = true
+		}
+		if err := watcher.Add(dir); err != nil {
+			log.Printf("Not watching %v: %v", src.File, err)
+		}
+	}
+	return func(name string) bool {
+		name = filepath.Clean(name)
+		return files[name] || dirs[filepath.Dir(name)] && !strings.HasPrefix(filepath.Base(name), ".")
+	}
+}
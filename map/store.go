@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"urlshort/persist"
+)
+
+// defaultDataDir is the per user configuration directory, falling back to
+// the working directory when the platform does not define one
+func defaultDataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "map-data"
+	}
+	return filepath.Join(dir, "map")
+}
+
+// location returns where backend name keeps its data inside dir
+func location(name, dir string) string {
+	switch name {
+	case "badger":
+		return filepath.Join(dir, "badger")
+	case "json":
+		return filepath.Join(dir, "links.json")
+	}
+	return ""
+}
+
+// legacyLocation returns where earlier versions kept backend name's data
+func legacyLocation(name string) string {
+	switch name {
+	case "badger":
+		return os.TempDir() + "badger"
+	case "json":
+		return filepath.Join(os.TempDir(), "map-links.json")
+	}
+	return ""
+}
+
+// openStore opens the named backend under dir. Ephemeral stores live in
+// memory only: badger runs in its in-memory mode and json falls back to the
+// memory backend.
+func openStore(name, dir string, ephemeral bool) (persist.Store, error) {
+	if ephemeral {
+		if name == "badger" {
+			return persist.OpenBadger("")
+		}
+		return persist.NewMemory(), nil
+	}
+	loc := location(name, dir)
+	if loc == "" {
+		return persist.Open(name, loc)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := migrate(legacyLocation(name), loc); err != nil {
+		log.Printf("Failed to migrate data from %v: %v", legacyLocation(name), err)
+	}
+	return persist.Open(name, loc)
+}
+
+// migrate copies data left at the old temporary location into dst the first
+// time the store is opened at dst. The old copy is left untouched.
+func migrate(src, dst string) error {
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		return err
+	}
+	fi, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Migrating data from %v to %v", src, dst)
+	if !fi.IsDir() {
+		return copyFile(src, dst)
+	}
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".migrating"
+	if err := os.MkdirAll(tmp, 0700); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, f.Name()), filepath.Join(tmp, f.Name())); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+	}
+	return os.Rename(tmp, dst)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,250 @@
+package urlshort
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Limits keeping scanners from filling the store with misses
+const (
+	maxMissLen     = 200   // longer paths are not recorded
+	maxMissPending = 1000  // distinct paths buffered between flushes
+	maxMisses      = 10000 // misses kept in the store, the least requested go first
+)
+
+// ignoredMisses are paths browsers and crawlers ask for on their own, never
+// recorded as misses
+var ignoredMisses = []string{"favicon.ico", "robots.txt", "apple-touch-icon*.png", "sitemap.xml", ".well-known/*"}
+
+// Miss counts a request for urlpath that nothing served. The count reaches
+// the store on the next Flush.
+func (t *Table) Miss(urlpath string, at time.Time) {
+	if urlpath == "" || len(urlpath) > maxMissLen {
+		return
+	}
+	for _, p := range ignoredMisses {
+		if ok, _ := path.Match(p, urlpath); ok {
+			return
+		}
+	}
+	t.missMu.Lock()
+	defer t.missMu.Unlock()
+	if t.misses == nil {
+		t.misses = make(map[string]*persist.Miss)
+	}
+	m, ok := t.misses[urlpath]
+	if !ok {
+		if len(t.misses) >= maxMissPending {
+			return
+		}
+		m = &persist.Miss{Path: urlpath, First: at}
+		t.misses[urlpath] = m
+	}
+	m.Count++
+	m.Last = at
+}
+
+// flushMisses writes the buffered misses to the store
+func (t *Table) flushMisses() error {
+	t.missMu.Lock()
+	pending := t.misses
+	t.misses = nil
+	t.missMu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	ms := make([]persist.Miss, 0, len(pending))
+	for _, m := range pending {
+		ms = append(ms, *m)
+	}
+	if err := t.store.RecordMisses(ms); err != nil {
+		log.Printf("Failed to record %v missed paths: %v", len(ms), err)
+		return err
+	}
+	// Counting the store is costly, so it is only pruned once enough paths
+	// have been recorded to possibly go over the limit
+	t.missMu.Lock()
+	t.missRecorded += len(ms)
+	prune := t.missRecorded >= maxMissPending
+	if prune {
+		t.missRecorded = 0
+	}
+	t.missMu.Unlock()
+	if prune {
+		if err := t.pruneMisses(maxMisses); err != nil {
+			log.Printf("Failed to prune missed paths: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneMisses drops the least and least recently requested misses while
+// the store holds more than max. Ignored misses were set by hand and stay.
+func (t *Table) pruneMisses(max int) error {
+	all, err := t.store.Misses()
+	if err != nil || len(all) <= max {
+		return err
+	}
+	var ms []persist.Miss
+	for _, m := range all {
+		if !m.Ignored {
+			ms = append(ms, m)
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Count != ms[j].Count {
+			return ms[i].Count < ms[j].Count
+		}
+		return ms[i].Last.Before(ms[j].Last)
+	})
+	n := len(all) - max
+	if n > len(ms) {
+		n = len(ms)
+	}
+	for _, m := range ms[:n] {
+		if err := t.store.DeleteMiss(m.Path); err != nil {
+			return err
+		}
+	}
+	log.Printf("Dropped %v of the least requested missed paths", n)
+	return nil
+}
+
+// Misses returns the recorded paths that are still not served, the most
+// requested first
+func (t *Table) Misses() ([]persist.Miss, error) {
+	if err := t.flushMisses(); err != nil {
+		return nil, err
+	}
+	all, err := t.store.Misses()
+	if err != nil {
+		return nil, err
+	}
+	rules := t.rules()
+	var ms []persist.Miss
+	for _, m := range all {
+		if _, _, ok := resolve(t.Lookup, rules, &url.URL{Path: "/" + m.Path}); !ok {
+			ms = append(ms, m)
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		a, b := ms[i], ms[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if !a.Last.Equal(b.Last) {
+			return a.Last.After(b.Last)
+		}
+		return a.Path < b.Path
+	})
+	return ms, nil
+}
+
+// missesPage is what the most wanted page shows
+type missesPage struct {
+	Wanted  []persist.Miss
+	Ignored []persist.Miss
+	Error   string
+	CSRF    string
+}
+
+// missesHandler ranks the paths people asked for that do not exist. Posting
+// action=create with a path and url creates the link, action=ignore and
+// action=unignore hide and show a path, and action=forget drops it.
+func missesHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var page missesPage
+		status := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			// The admin token may come from the browser on its own
+			if err := checkCSRF(r); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if err := missAction(table, r); err != nil {
+				page.Error, status = err.Error(), http.StatusBadRequest
+				break
+			}
+			http.Redirect(w, r, opts.page("admin/misses"), http.StatusSeeOther)
+			return
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ms, err := table.Misses()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, m := range ms {
+			if m.Ignored {
+				page.Ignored = append(page.Ignored, m)
+			} else {
+				page.Wanted = append(page.Wanted, m)
+			}
+		}
+		page.CSRF = csrfToken(w, r, opts)
+		missesTemplate, err := parseTemplate("misses", opts)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "cannot show the page", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		render(w, r, missesTemplate, "misses", page)
+	}
+}
+
+// missAction applies the action posted from the most wanted page
+func missAction(table *Table, r *http.Request) error {
+	p := strings.Trim(r.FormValue("path"), "/")
+	if p == "" {
+		return errors.New("no path given")
+	}
+	store := table.Store()
+	switch r.FormValue("action") {
+	case "create":
+		s := persist.Short{Path: p, Site: strings.TrimSpace(r.FormValue("url"))}
+		if err := checkLink(s); err != nil {
+			return err
+		}
+		if err := checkAbsolute(s.Site); err != nil {
+			return err
+		}
+		if err := table.checkReserved(s.Path); err != nil {
+			return err
+		}
+		err := table.SaveIf(s, func(old persist.Short, exists bool) error {
+			if exists {
+				return errors.New("/" + s.Path + " already exists")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Created %v -> %v", s.Path, s.Site)
+		return nil
+	case "ignore", "unignore":
+		table.flushMisses()
+		return store.IgnoreMiss(p, r.FormValue("action") == "ignore")
+	case "forget":
+		table.flushMisses()
+		return store.DeleteMiss(p)
+	}
+	return fmt.Errorf("unknown action %q", r.FormValue("action"))
+}
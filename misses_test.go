@@ -0,0 +1,121 @@
+package urlshort
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"urlshort/persist"
+)
+
+func TestPruneMisses(t *testing.T) {
+	store := persist.NewMemory()
+	table, err := NewTable(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	var ms []persist.Miss
+	for i := 0; i < 6; i++ {
+		// Counts 1, 1, 2, 2, 3, 3, the later of each pair asked for last
+		ms = append(ms, persist.Miss{Path: fmt.Sprint("p", i), Count: i/2 + 1, First: now, Last: now.Add(time.Duration(i) * time.Minute)})
+	}
+	if err := store.RecordMisses(ms); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.IgnoreMiss("p0", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.pruneMisses(3); err != nil {
+		t.Fatal(err)
+	}
+	left, err := store.Misses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, m := range left {
+		paths = append(paths, m.Path)
+	}
+	sort.Strings(paths)
+	if fmt.Sprint(paths) != "[p0 p4 p5]" {
+		t.Errorf("kept %v, want the ignored p0 and the most requested p4 and p5", paths)
+	}
+}
+
+// TestMissLimit checks that a flood of distinct missed paths does not grow
+// the store past maxMisses
+func TestMissLimit(t *testing.T) {
+	store := persist.NewMemory()
+	table, err := NewTable(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < maxMisses+3*maxMissPending; i++ {
+		table.Miss(fmt.Sprint("scan/", i), now)
+		if i%maxMissPending == 0 {
+			if err := table.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := table.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	ms, err := store.Misses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) > maxMisses+maxMissPending {
+		t.Errorf("store holds %v misses, want at most %v", len(ms), maxMisses+maxMissPending)
+	}
+}
+
+// TestMissesForms checks that the misses page takes no cross-site posts and
+// creates only links to absolute URLs
+func TestMissesForms(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := SetHandler(table, Options{AdminToken: "s3"})
+	post := func(form url.Values, cookie string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/admin/misses", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Authorization", "Bearer s3")
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: csrfCookie, Value: cookie})
+		}
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	form := url.Values{"action": {"create"}, "path": {"evil"}, "url": {"https://example.com"}}
+	if code := post(form, ""); code != http.StatusForbidden {
+		t.Errorf("create without a form token = %v, want %v", code, http.StatusForbidden)
+	}
+	if _, ok := table.Lookup("evil"); ok {
+		t.Error("a cross-site post created /evil")
+	}
+
+	form.Set("csrf", "t0k")
+	form.Set("url", "javascript:alert(1)")
+	if code := post(form, "t0k"); code != http.StatusBadRequest {
+		t.Errorf("create of a script URL = %v, want %v", code, http.StatusBadRequest)
+	}
+	form.Set("url", "https://example.com")
+	if code := post(form, "t0k"); code != http.StatusSeeOther {
+		t.Errorf("create = %v, want %v", code, http.StatusSeeOther)
+	}
+	if _, ok := table.Lookup("evil"); !ok {
+		t.Error("create did not save /evil")
+	}
+}
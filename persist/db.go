@@ -3,53 +3,131 @@ package persist
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"net/url"
+	"sync"
+	"time"
 
 	"github.com/dgraph-io/badger/v2"
 )
 
+// Short is a single link and what is known about it
 type Short struct {
-	Path  string
-	Site  string
-	Count int
+	Path        string
+	Site        string
+	Count       int
+	Created     time.Time
+	Updated     time.Time
+	LastVisited time.Time
+	Creator     string
+	Description string
+	Tags        []string
+	Forward     Forward
+	// Generated is set on links whose path is a short code picked by the
+	// server rather than by a person
+	Generated bool
 }
 
+// Forward says how the parts of a request beyond the link path reach the
+// target. The zero value appends extra path and merges the query.
+type Forward struct {
+	Path     string // PathAppend, PathDrop or PathExact
+	Query    string // QueryMerge, QueryReplace or QueryDrop
+	Fragment string // replaces the fragment of the target when set
+	Params   string // URL encoded query parameters added to every redirect
+}
+
+// Forwarding modes. The empty string selects the first of each.
+const (
+	PathAppend = "append" // append extra path to the target
+	PathDrop   = "drop"   // redirect to the target, ignoring extra path
+	PathExact  = "exact"  // only answer the link path itself
+
+	QueryMerge   = "merge"   // add the request query to the target's
+	QueryReplace = "replace" // use the request query instead of the target's
+	QueryDrop    = "drop"    // ignore the request query
+)
+
+// Check reports invalid modes or parameters
+func (f Forward) Check() error {
+	switch f.Path {
+	case "", PathAppend, PathDrop, PathExact:
+	default:
+		return fmt.Errorf("unknown path mode %q", f.Path)
+	}
+	switch f.Query {
+	case "", QueryMerge, QueryReplace, QueryDrop:
+	default:
+		return fmt.Errorf("unknown query mode %q", f.Query)
+	}
+	if _, err := url.ParseQuery(f.Params); err != nil {
+		return fmt.Errorf("bad params %q: %v", f.Params, err)
+	}
+	return nil
+}
+
+// database is the Badger backed Store
 type database struct {
 	DB   *badger.DB
 	file string
 	opts badger.Options
-}
 
-// Db Struct that holds database handle
-var Db database
+	seqMu sync.Mutex
+	seq   *badger.Sequence // leased on first use
+}
 
-func (db *database) Open() {
+// OpenBadger opens the Badger database in dir
+func OpenBadger(dir string) (Store, error) {
 	var err error
-	db.file = "badger"
+	db := &database{file: dir}
 	if db.file == "" {
 		db.opts = badger.DefaultOptions("").WithInMemory(true)
 	} else {
-		db.opts = badger.DefaultOptions(os.TempDir() + db.file)
+		db.opts = badger.DefaultOptions(db.file)
 	}
 	db.opts.Logger = nil
 	db.DB, err = badger.Open(db.opts)
 	if err != nil {
-		log.Fatalf("Failed to open DB: %v", err)
+		return nil, err
 	}
+	return db, nil
 }
 
-func (s *Short) gobEncode() ([]byte, error) {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	err := enc.Encode(s)
-	if err != nil {
-		return nil, err
+// Close the underlying database
+func (db *database) Close() error {
+	db.seqMu.Lock()
+	if db.seq != nil {
+		db.seq.Release()
+		db.seq = nil
 	}
-	return buf.Bytes(), nil
+	db.seqMu.Unlock()
+	return db.DB.Close()
+}
+
+// seqKey holds the sequence short codes are numbered from
+const seqKey = metaPrefix + "seq/codes"
 
+// NextSequence returns the next number from a Badger sequence. Numbers are
+// leased in blocks, so the ones leased but unused are skipped after a
+// restart.
+func (db *database) NextSequence() (uint64, error) {
+	db.seqMu.Lock()
+	defer db.seqMu.Unlock()
+	if db.seq == nil {
+		seq, err := db.DB.GetSequence([]byte(seqKey), 100)
+		if err != nil {
+			return 0, err
+		}
+		db.seq = seq
+	}
+	n, err := db.seq.Next()
+	// Sequences start at zero
+	return n + 1, err
 }
 
+// gobDecode reads records written before the versioned record format
 func gobDecode(d []byte) (*Short, error) {
 	var s *Short
 	buf := bytes.NewBuffer(d)
@@ -61,50 +139,112 @@ func gobDecode(d []byte) (*Short, error) {
 	return s, nil
 }
 
-// Saves key value to DB
+// Saves key value to DB. Keys that do not fit one transaction go to the
+// next; each key is read and written in the same transaction, which is
+// retried when an Incr commits in between.
 func (db *database) SaveMap(m map[string]Short) error {
-	txn := db.DB.NewTransaction(true)
-	for k, v := range m {
-		gb, _ := v.gobEncode()
-		if err := txn.Set([]byte(k), gb); err == badger.ErrTxnTooBig {
-			_ = txn.Commit()
-			txn = db.DB.NewTransaction(true)
-			_ = txn.Set([]byte(k), gb)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	for len(keys) > 0 {
+		var n int
+		err := db.update(func(txn *badger.Txn) error {
+			for n = 0; n < len(keys); n++ {
+				k := keys[n]
+				v := m[k]
+				old, err := getShort(txn, k)
+				switch {
+				case err == nil:
+					v.keepVisits(*old)
+				case err == ErrNotFound, errors.Is(err, ErrCorrupt):
+					// Nothing to keep, a corrupt record is overwritten
+				default:
+					return err
+				}
+				if err := txn.Set([]byte(k), v.encode()); err == badger.ErrTxnTooBig && n > 0 {
+					// Commit the keys that fit
+					return nil
+				} else if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
+		keys = keys[n:]
 	}
-	return txn.Commit()
+	return nil
 }
 
 // Save single key to DB
 func (db *database) Save(s Short) error {
 	err := db.DB.Update(func(txn *badger.Txn) error {
-		gb, _ := s.gobEncode()
-		return txn.Set([]byte(s.Path), []byte(gb))
+		return txn.Set([]byte(s.Path), s.encode())
 	})
 	return err
 }
 
-// Get single key from DB
-func (db *database) Get(k string) (*Short, bool) {
-	var tr *Short
-	err := db.DB.View(func(txn *badger.Txn) error {
-		i, err := txn.Get([]byte(k))
+// Incr adds n to the count of key k in a single read-modify-write
+// transaction, retrying when a concurrent update wins the race
+func (db *database) Incr(k string, n int, last time.Time) error {
+	return db.update(func(txn *badger.Txn) error {
+		s, err := getShort(txn, k)
 		if err != nil {
 			return err
 		}
-		data, _ := i.ValueCopy(nil)
-		tr, err = gobDecode(data)
-		return err
+		s.visit(n, last)
+		return txn.Set([]byte(k), s.encode())
 	})
+}
+
+// getShort reads and decodes the link under k
+func getShort(txn *badger.Txn, k string) (*Short, error) {
+	i, err := txn.Get([]byte(k))
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := i.ValueCopy(nil)
 	if err != nil {
-		return tr, false
+		return nil, err
 	}
-	return tr, true
+	s, err := decode(data)
+	if err != nil {
+		return nil, &CorruptError{Keys: []string{k}, Err: err}
+	}
+	return s, nil
+}
+
+// update runs fn in a read-write transaction, retrying on conflicts
+func (db *database) update(fn func(txn *badger.Txn) error) error {
+	for {
+		err := db.DB.Update(fn)
+		if err != badger.ErrConflict {
+			return err
+		}
+	}
+}
+
+// Get single key from DB
+func (db *database) Get(k string) (*Short, error) {
+	var tr *Short
+	err := db.DB.View(func(txn *badger.Txn) error {
+		var err error
+		tr, err = getShort(txn, k)
+		return err
+	})
+	return tr, err
 }
 
 // Get all data from DB
-func (db *database) GetAll() (map[string]Short, bool) {
+func (db *database) GetAll() (map[string]Short, error) {
 	var ga = make(map[string]Short)
+	corrupt := &CorruptError{}
 	err := db.DB.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchSize = 10
@@ -113,8 +253,15 @@ func (db *database) GetAll() (map[string]Short, bool) {
 		for it.Rewind(); it.Valid(); it.Next() {
 			item := it.Item()
 			k := item.Key()
+			if isMeta(k) {
+				continue
+			}
 			err := item.Value(func(v []byte) error {
-				vv, _ := gobDecode(v)
+				vv, err := decode(v)
+				if err != nil {
+					corrupt.add(string(k), err)
+					return nil
+				}
 				ga[string(k)] = *vv
 				return nil
 			})
@@ -125,7 +272,87 @@ func (db *database) GetAll() (map[string]Short, bool) {
 		return nil
 	})
 	if err != nil {
-		return ga, false
+		return ga, err
+	}
+	return ga, corrupt.orNil()
+}
+
+// Delete single key and its visit history from DB
+func (db *database) Delete(k string) error {
+	err := db.update(func(txn *badger.Txn) error {
+		if _, err := txn.Get([]byte(k)); err == badger.ErrKeyNotFound {
+			return ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		return txn.Delete([]byte(k))
+	})
+	if err != nil {
+		return err
+	}
+	return db.deleteStats(k)
+}
+
+// Migrate rewrites records still stored in the legacy gob format. Each
+// record is converted in its own transaction so it can run while the
+// server is busy updating counts.
+func (db *database) Migrate() (int, error) {
+	var keys [][]byte
+	err := db.DB.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isMeta(item.Key()) {
+				continue
+			}
+			err := item.Value(func(v []byte) error {
+				if isLegacy(v) {
+					keys = append(keys, item.KeyCopy(nil))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, k := range keys {
+		if err := db.rewrite(k); err != nil {
+			log.Printf("Failed to migrate record %q: %v", k, err)
+			continue
+		}
+		n++
 	}
-	return ga, true
+	return n, nil
+}
+
+// rewrite re-encodes a single legacy record in the current format
+func (db *database) rewrite(k []byte) error {
+	return db.update(func(txn *badger.Txn) error {
+		i, err := txn.Get(k)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := i.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if !isLegacy(data) {
+			return nil
+		}
+		s, err := gobDecode(data)
+		if err != nil {
+			return err
+		}
+		return txn.Set(k, s.encode())
+	})
 }
@@ -0,0 +1,124 @@
+package persist
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/pb"
+)
+
+// bitDelete marks a deleted key in the meta byte of a backup entry
+const bitDelete = 1 << 0
+
+// Backup streams a full Badger backup of every key to w
+func (db *database) Backup(w io.Writer) error {
+	_, err := db.DB.Backup(w, 0)
+	return err
+}
+
+// Restore replaces the contents of the database with a backup written by
+// Backup. Badger's own Load must not run alongside other transactions and
+// keeps the value pointer bit of entries that lived in the value log, so
+// the backup stream is decoded here and copied over with a write batch,
+// which is safe while the server is running. The whole backup is read
+// before anything is written, and the previous contents are written back
+// if copying it over fails half way.
+func (db *database) Restore(r io.Reader) error {
+	backup, err := readBackup(r)
+	if err != nil {
+		return err
+	}
+	current, err := db.dump()
+	if err != nil {
+		return err
+	}
+	if err := db.replace(current, backup); err != nil {
+		if rerr := db.replace(backup, current); rerr != nil {
+			return fmt.Errorf("%v, and putting the previous contents back failed: %v", err, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// readBackup decodes the live keys of a backup written by Backup
+func readBackup(r io.Reader) (map[string][]byte, error) {
+	kvs := make(map[string][]byte)
+	seen := make(map[string]bool)
+	br := bufio.NewReaderSize(r, 16<<10)
+	for {
+		var sz uint64
+		err := binary.Read(br, binary.LittleEndian, &sz)
+		if err == io.EOF {
+			return kvs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, sz)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		list := &pb.KVList{}
+		if err := list.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		for _, kv := range list.Kv {
+			// Versions of a key arrive newest first, only the first counts
+			if seen[string(kv.Key)] {
+				continue
+			}
+			seen[string(kv.Key)] = true
+			if len(kv.Meta) > 0 && kv.Meta[0]&bitDelete != 0 {
+				continue
+			}
+			if kv.ExpiresAt != 0 && kv.ExpiresAt <= uint64(time.Now().Unix()) {
+				continue
+			}
+			kvs[string(kv.Key)] = kv.Value
+		}
+	}
+}
+
+// dump returns a copy of every key in the database
+func (db *database) dump() (map[string][]byte, error) {
+	kvs := make(map[string][]byte)
+	err := db.DB.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			v, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			kvs[string(it.Item().KeyCopy(nil))] = v
+		}
+		return nil
+	})
+	return kvs, err
+}
+
+// replace turns the database holding old into one holding kvs, deleting
+// the keys of old that kvs lacks
+func (db *database) replace(old, kvs map[string][]byte) error {
+	wb := db.DB.NewWriteBatch()
+	defer wb.Cancel()
+	for k, v := range kvs {
+		if err := wb.Set([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	for k := range old {
+		if _, ok := kvs[k]; ok {
+			continue
+		}
+		if err := wb.Delete([]byte(k)); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
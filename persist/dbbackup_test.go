@@ -0,0 +1,66 @@
+package persist
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "map-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := OpenBadger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	backedUp := map[string]Short{
+		"nyt": {Path: "nyt", Site: "https://www.nytimes.com", Count: 3},
+		"go":  {Path: "go", Site: "https://golang.org"},
+	}
+	if err := db.SaveMap(backedUp); err != nil {
+		t.Fatal(err)
+	}
+	var backup bytes.Buffer
+	if err := db.Backup(&backup); err != nil {
+		t.Fatal(err)
+	}
+
+	current := map[string]Short{
+		"nyt": {Path: "nyt", Site: "https://www.nytimes.com/section/world", Count: 5},
+		"gh":  {Path: "gh", Site: "https://github.com"},
+	}
+	if err := db.Delete("go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveMap(current); err != nil {
+		t.Fatal(err)
+	}
+	check := func(want map[string]Short) {
+		t.Helper()
+		got, err := db.GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("store holds %v, want %v", got, want)
+		}
+	}
+
+	truncated := bytes.NewReader(backup.Bytes()[:backup.Len()-1])
+	if err := db.Restore(truncated); err == nil {
+		t.Error("restore of a truncated backup succeeded")
+	}
+	check(current)
+
+	if err := db.Restore(bytes.NewReader(backup.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	check(backedUp)
+}
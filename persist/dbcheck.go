@@ -0,0 +1,94 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// Quarantined records are kept under their original key behind this prefix
+const quarantinePrefix = metaPrefix + "q/"
+
+// Check walks every key, decoding links and visit buckets, and reports the
+// ones that are corrupt or inconsistent. Quarantined records are skipped.
+func (db *database) Check() ([]Problem, error) {
+	var ps []Problem
+	err := db.DB.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			k := item.KeyCopy(nil)
+			err := item.Value(func(v []byte) error {
+				ps = append(ps, checkRecord(k, v)...)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ps, err
+}
+
+// checkRecord reports what is wrong with value v stored under key k
+func checkRecord(k, v []byte) []Problem {
+	switch {
+	case bytes.HasPrefix(k, []byte(quarantinePrefix)):
+		return nil
+	case bytes.HasPrefix(k, []byte(statsPrefix)):
+		if _, _, _, ok := parseStatsKey(k); !ok {
+			return []Problem{{string(k), "is not a valid visit bucket key"}}
+		}
+		var b Bucket
+		if err := json.Unmarshal(v, &b); err != nil {
+			return []Problem{{string(k), "visit bucket cannot be decoded: " + err.Error()}}
+		}
+		return nil
+	case bytes.HasPrefix(k, []byte(missPrefix)):
+		var m Miss
+		if err := json.Unmarshal(v, &m); err != nil {
+			return []Problem{{string(k), "miss cannot be decoded: " + err.Error()}}
+		}
+		return nil
+	case string(k) == seqKey:
+		return nil
+	case isMeta(k):
+		return []Problem{{string(k), "has an unknown internal prefix"}}
+	}
+	s, err := decode(v)
+	if err != nil {
+		return []Problem{{string(k), "cannot be decoded: " + err.Error()}}
+	}
+	return checkShort(string(k), *s)
+}
+
+// Quarantine moves each key under the quarantine prefix, one transaction
+// per key. The raw value is kept so it can be inspected or repaired later.
+func (db *database) Quarantine(keys []string) error {
+	for _, k := range keys {
+		err := db.update(func(txn *badger.Txn) error {
+			i, err := txn.Get([]byte(k))
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			v, err := i.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set([]byte(quarantinePrefix+k), v); err != nil {
+				return err
+			}
+			return txn.Delete([]byte(k))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
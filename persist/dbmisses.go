@@ -0,0 +1,97 @@
+package persist
+
+import (
+	"encoding/json"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// Misses are kept as JSON under the path behind this prefix
+const missPrefix = metaPrefix + "m/"
+
+func missKey(path string) []byte {
+	return []byte(missPrefix + path)
+}
+
+// getMiss reads the miss at path, returning a new empty one when missing
+func getMiss(txn *badger.Txn, path string) (*Miss, error) {
+	i, err := txn.Get(missKey(path))
+	if err == badger.ErrKeyNotFound {
+		return &Miss{Path: path}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Miss{}
+	err = i.Value(func(v []byte) error {
+		return json.Unmarshal(v, m)
+	})
+	return m, err
+}
+
+func setMiss(txn *badger.Txn, m *Miss) error {
+	v, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return txn.Set(missKey(m.Path), v)
+}
+
+// RecordMisses adds ms to the recorded misses, one transaction per path
+func (db *database) RecordMisses(ms []Miss) error {
+	for _, o := range ms {
+		o := o
+		err := db.update(func(txn *badger.Txn) error {
+			m, err := getMiss(txn, o.Path)
+			if err != nil {
+				return err
+			}
+			m.merge(o)
+			return setMiss(txn, m)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Misses reads every recorded miss
+func (db *database) Misses() ([]Miss, error) {
+	var ms []Miss
+	err := db.DB.View(func(txn *badger.Txn) error {
+		prefix := []byte(missPrefix)
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var m Miss
+			if err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, &m)
+			}); err != nil {
+				return err
+			}
+			ms = append(ms, m)
+		}
+		return nil
+	})
+	return ms, err
+}
+
+// IgnoreMiss sets whether the miss at path is ignored
+func (db *database) IgnoreMiss(path string, ignore bool) error {
+	return db.update(func(txn *badger.Txn) error {
+		m, err := getMiss(txn, path)
+		if err != nil {
+			return err
+		}
+		m.Ignored = ignore
+		return setMiss(txn, m)
+	})
+}
+
+// DeleteMiss forgets the miss at path
+func (db *database) DeleteMiss(path string) error {
+	return db.update(func(txn *badger.Txn) error {
+		return txn.Delete(missKey(path))
+	})
+}
@@ -0,0 +1,212 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// Keys starting with a zero byte hold data other than links. URL paths
+// are trimmed of their leading slash and never start with one.
+const (
+	metaPrefix  = "\x00"
+	statsPrefix = metaPrefix + "v/"
+	keyHour     = "2006010215"
+)
+
+// isMeta reports whether k holds something other than a link
+func isMeta(k []byte) bool {
+	return bytes.HasPrefix(k, []byte(metaPrefix))
+}
+
+// statsPathPrefix is the prefix shared by every bucket of path. The path is
+// terminated with a zero byte so one path is never a prefix of another.
+func statsPathPrefix(path string) []byte {
+	return []byte(statsPrefix + path + "\x00")
+}
+
+func statsKey(path, span string, start time.Time) []byte {
+	return append(statsPathPrefix(path), span+"/"+start.Format(keyHour)...)
+}
+
+// parseStatsKey splits a bucket key into its path, span and start
+func parseStatsKey(k []byte) (string, string, time.Time, bool) {
+	s := strings.TrimPrefix(string(k), statsPrefix)
+	i := strings.LastIndex(s, "\x00")
+	if i < 0 {
+		return "", "", time.Time{}, false
+	}
+	parts := strings.SplitN(s[i+1:], "/", 2)
+	if len(parts) != 2 {
+		return "", "", time.Time{}, false
+	}
+	start, err := time.Parse(keyHour, parts[1])
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+	return s[:i], parts[0], start, true
+}
+
+// getBucket reads the bucket at k, returning a new empty one when missing
+func getBucket(txn *badger.Txn, k []byte, span string, start time.Time) (*Bucket, error) {
+	i, err := txn.Get(k)
+	if err == badger.ErrKeyNotFound {
+		return newBucket(span, start), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b := newBucket(span, start)
+	err = i.Value(func(v []byte) error {
+		return json.Unmarshal(v, b)
+	})
+	return b, err
+}
+
+func setBucket(txn *badger.Txn, k []byte, b *Bucket) error {
+	v, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return txn.Set(k, v)
+}
+
+// RecordVisits adds visits to their hourly buckets, one transaction per bucket
+func (db *database) RecordVisits(vs []Visit) error {
+	st := make(statsTable)
+	st.record(vs)
+	for path, buckets := range st {
+		for _, b := range buckets {
+			b := b
+			k := statsKey(path, Hour, b.Start)
+			err := db.update(func(txn *badger.Txn) error {
+				old, err := getBucket(txn, k, Hour, b.Start)
+				if err != nil {
+					return err
+				}
+				old.merge(b)
+				return setBucket(txn, k, old)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stats reads every bucket of path
+func (db *database) Stats(path string) (*Stats, error) {
+	s := &Stats{Path: path}
+	var buckets []*Bucket
+	err := db.DB.View(func(txn *badger.Txn) error {
+		prefix := statsPathPrefix(path)
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			b := &Bucket{}
+			if err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, b)
+			}); err != nil {
+				return err
+			}
+			buckets = append(buckets, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.sortBuckets(buckets)
+	return s, nil
+}
+
+// Rollup merges hourly buckets that started before cutoff into daily
+// buckets. Each day of each path is merged in its own transaction.
+func (db *database) Rollup(cutoff time.Time) error {
+	days := make(map[string][][]byte)
+	err := db.DB.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := []byte(statsPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			k := it.Item().KeyCopy(nil)
+			path, span, start, ok := parseStatsKey(k)
+			if !ok || span != Hour || !start.Before(cutoff) {
+				continue
+			}
+			dk := string(statsKey(path, Day, spanStart(Day, start)))
+			days[dk] = append(days[dk], k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for dk, hours := range days {
+		dk, hours := []byte(dk), hours
+		_, _, start, _ := parseStatsKey(dk)
+		err := db.update(func(txn *badger.Txn) error {
+			day, err := getBucket(txn, dk, Day, start)
+			if err != nil {
+				return err
+			}
+			for _, hk := range hours {
+				i, err := txn.Get(hk)
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				h := &Bucket{}
+				if err := i.Value(func(v []byte) error {
+					return json.Unmarshal(v, h)
+				}); err != nil {
+					return err
+				}
+				day.merge(h)
+				if err := txn.Delete(hk); err != nil {
+					return err
+				}
+			}
+			return setBucket(txn, dk, day)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStats removes every bucket of path
+func (db *database) deleteStats(path string) error {
+	var keys [][]byte
+	err := db.DB.View(func(txn *badger.Txn) error {
+		prefix := statsPathPrefix(path)
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	wb := db.DB.NewWriteBatch()
+	defer wb.Cancel()
+	for _, k := range keys {
+		if err := wb.Delete(k); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
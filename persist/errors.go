@@ -0,0 +1,75 @@
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when no link is stored under a key
+	ErrNotFound = errors.New("link not found")
+	// ErrCorrupt matches every CorruptError
+	ErrCorrupt = errors.New("corrupt record")
+)
+
+// CorruptError lists the keys whose records could not be decoded
+type CorruptError struct {
+	Keys []string
+	Err  error // the first decode error
+}
+
+func (e *CorruptError) Error() string {
+	return fmt.Sprintf("corrupt record %v: %v", strings.Join(e.Keys, ", "), e.Err)
+}
+
+// Unwrap returns the first decode error
+func (e *CorruptError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrCorrupt
+func (e *CorruptError) Is(target error) bool {
+	return target == ErrCorrupt
+}
+
+// add records that the record under k failed to decode with err
+func (e *CorruptError) add(k string, err error) {
+	if e.Err == nil {
+		e.Err = err
+	}
+	e.Keys = append(e.Keys, k)
+}
+
+// orNil returns e if any key was added to it
+func (e *CorruptError) orNil() error {
+	if len(e.Keys) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Problem is an inconsistency found by Check
+type Problem struct {
+	Key    string
+	Reason string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%q: %v", p.Key, p.Reason)
+}
+
+// checkShort returns what is wrong with the link s stored under key k
+func checkShort(k string, s Short) []Problem {
+	var ps []Problem
+	if s.Path != k {
+		ps = append(ps, Problem{k, fmt.Sprintf("stored under a key that differs from its path %q", s.Path)})
+	}
+	if s.Site == "" {
+		ps = append(ps, Problem{k, "has no target URL"})
+	}
+	if s.Count < 0 {
+		ps = append(ps, Problem{k, fmt.Sprintf("has a negative visit count %v", s.Count)})
+	}
+	return ps
+}
@@ -0,0 +1,181 @@
+package persist
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// jsonFile keeps the whole link table in memory and rewrites a single
+// JSON file after every change
+type jsonFile struct {
+	memory
+	file string
+}
+
+// jsonData is the layout of the file. Files holding only the links map,
+// written before visit history was kept, are still read.
+type jsonData struct {
+	Links      map[string]Short
+	Stats      statsTable
+	Misses     missTable        `json:",omitempty"`
+	Quarantine map[string]Short `json:",omitempty"`
+	Sequence   uint64           `json:",omitempty"`
+}
+
+// OpenJSON loads the links stored in file. A missing file is treated as an
+// empty store and is created on the first write.
+func OpenJSON(file string) (Store, error) {
+	j := &jsonFile{memory: *newMemory(), file: file}
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return j, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	if _, ok := raw["Links"]; !ok {
+		return j, json.Unmarshal(b, &j.links)
+	}
+	d := jsonData{Links: j.links, Stats: j.stats, Misses: j.misses, Quarantine: j.quarantine, Sequence: j.seq}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+	if d.Links != nil {
+		j.links = d.Links
+	}
+	if d.Stats != nil {
+		j.stats = d.Stats
+	}
+	if d.Misses != nil {
+		j.misses = d.Misses
+	}
+	if d.Quarantine != nil {
+		j.quarantine = d.Quarantine
+	}
+	j.seq = d.Sequence
+	return j, nil
+}
+
+func (j *jsonFile) Save(s Short) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.links[s.Path] = s
+	return j.write()
+}
+
+func (j *jsonFile) SaveMap(sm map[string]Short) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.saveMap(sm)
+	return j.write()
+}
+
+func (j *jsonFile) Incr(k string, n int, last time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.incr(k, n, last); err != nil {
+		return err
+	}
+	return j.write()
+}
+
+func (j *jsonFile) RecordVisits(vs []Visit) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.record(vs)
+	return j.write()
+}
+
+func (j *jsonFile) Rollup(cutoff time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stats.rollup(cutoff)
+	return j.write()
+}
+
+func (j *jsonFile) RecordMisses(ms []Miss) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.misses.record(ms)
+	return j.write()
+}
+
+func (j *jsonFile) IgnoreMiss(path string, ignore bool) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.misses.ignore(path, ignore)
+	return j.write()
+}
+
+func (j *jsonFile) DeleteMiss(path string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.misses, path)
+	return j.write()
+}
+
+func (j *jsonFile) NextSequence() (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.seq++
+	return j.seq, j.write()
+}
+
+func (j *jsonFile) Delete(k string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.delete(k); err != nil {
+		return err
+	}
+	return j.write()
+}
+
+func (j *jsonFile) Restore(r io.Reader) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.restore(r); err != nil {
+		return err
+	}
+	return j.write()
+}
+
+func (j *jsonFile) Quarantine(keys []string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.isolate(keys)
+	return j.write()
+}
+
+// write replaces the file through a rename so readers never see a
+// partially written table. Callers must hold the write lock.
+func (j *jsonFile) write() error {
+	b, err := json.MarshalIndent(jsonData{Links: j.links, Stats: j.stats, Misses: j.misses, Quarantine: j.quarantine, Sequence: j.seq}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(j.file), filepath.Base(j.file)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), j.file)
+}
@@ -0,0 +1,233 @@
+package persist
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// memory keeps links in a map and loses them on exit
+type memory struct {
+	mu         sync.RWMutex
+	links      map[string]Short
+	stats      statsTable
+	misses     missTable
+	quarantine map[string]Short
+	seq        uint64
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() Store {
+	return newMemory()
+}
+
+func newMemory() *memory {
+	return &memory{
+		links:      make(map[string]Short),
+		stats:      make(statsTable),
+		misses:     make(missTable),
+		quarantine: make(map[string]Short),
+	}
+}
+
+func (m *memory) Get(k string) (*Short, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.links[k]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *memory) Save(s Short) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links[s.Path] = s
+	return nil
+}
+
+func (m *memory) SaveMap(sm map[string]Short) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saveMap(sm)
+	return nil
+}
+
+// saveMap stores sm in place. Callers must hold the write lock.
+func (m *memory) saveMap(sm map[string]Short) {
+	for k, v := range sm {
+		if old, ok := m.links[k]; ok {
+			v.keepVisits(old)
+		}
+		m.links[k] = v
+	}
+}
+
+func (m *memory) GetAll() (map[string]Short, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ga := make(map[string]Short, len(m.links))
+	for k, v := range m.links {
+		ga[k] = v
+	}
+	return ga, nil
+}
+
+func (m *memory) Incr(k string, n int, last time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.incr(k, n, last)
+}
+
+// incr updates the count in place. Callers must hold the write lock.
+func (m *memory) incr(k string, n int, last time.Time) error {
+	s, ok := m.links[k]
+	if !ok {
+		return ErrNotFound
+	}
+	s.visit(n, last)
+	m.links[k] = s
+	return nil
+}
+
+func (m *memory) RecordVisits(vs []Visit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.record(vs)
+	return nil
+}
+
+func (m *memory) Stats(path string) (*Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats.stats(path), nil
+}
+
+func (m *memory) Rollup(cutoff time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.rollup(cutoff)
+	return nil
+}
+
+func (m *memory) RecordMisses(ms []Miss) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses.record(ms)
+	return nil
+}
+
+func (m *memory) Misses() ([]Miss, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.misses.list(), nil
+}
+
+func (m *memory) IgnoreMiss(path string, ignore bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses.ignore(path, ignore)
+	return nil
+}
+
+func (m *memory) DeleteMiss(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.misses, path)
+	return nil
+}
+
+func (m *memory) NextSequence() (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	return m.seq, nil
+}
+
+func (m *memory) Delete(k string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.delete(k)
+}
+
+// delete removes k and its history. Callers must hold the write lock.
+func (m *memory) delete(k string) error {
+	if _, ok := m.links[k]; !ok {
+		return ErrNotFound
+	}
+	delete(m.links, k)
+	delete(m.stats, k)
+	return nil
+}
+
+func (m *memory) Check() ([]Problem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var ps []Problem
+	for k, v := range m.links {
+		ps = append(ps, checkShort(k, v)...)
+	}
+	return ps, nil
+}
+
+func (m *memory) Quarantine(keys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isolate(keys)
+	return nil
+}
+
+// isolate moves keys to the quarantine. Callers must hold the write lock.
+func (m *memory) isolate(keys []string) {
+	for _, k := range keys {
+		if s, ok := m.links[k]; ok {
+			m.quarantine[k] = s
+			delete(m.links, k)
+		}
+	}
+}
+
+func (m *memory) Close() error {
+	return nil
+}
+
+// Backup writes the store in the same layout as the json backend's file
+func (m *memory) Backup(w io.Writer) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonData{Links: m.links, Stats: m.stats, Misses: m.misses, Quarantine: m.quarantine, Sequence: m.seq})
+}
+
+func (m *memory) Restore(r io.Reader) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restore(r)
+}
+
+// restore replaces the contents of m. Callers must hold the write lock.
+func (m *memory) restore(r io.Reader) error {
+	var d jsonData
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return err
+	}
+	n := newMemory()
+	for k, v := range d.Links {
+		n.links[k] = v
+	}
+	for k, v := range d.Stats {
+		n.stats[k] = v
+	}
+	for k, v := range d.Misses {
+		n.misses[k] = v
+	}
+	for k, v := range d.Quarantine {
+		n.quarantine[k] = v
+	}
+	m.links, m.stats, m.misses, m.quarantine = n.links, n.stats, n.misses, n.quarantine
+	m.seq = d.Sequence
+	return nil
+}
@@ -0,0 +1,55 @@
+package persist
+
+import "time"
+
+// Miss counts the requests for a path that no link served
+type Miss struct {
+	Path  string
+	Count int
+	First time.Time
+	Last  time.Time
+	// Ignored misses are still counted but left out of rankings
+	Ignored bool `json:",omitempty"`
+}
+
+// merge adds the requests counted in o to m
+func (m *Miss) merge(o Miss) {
+	m.Count += o.Count
+	if m.First.IsZero() || !o.First.IsZero() && o.First.Before(m.First) {
+		m.First = o.First
+	}
+	if o.Last.After(m.Last) {
+		m.Last = o.Last
+	}
+}
+
+// missTable keeps misses in memory for the memory and json stores
+type missTable map[string]Miss
+
+func (mt missTable) record(ms []Miss) {
+	for _, o := range ms {
+		m, ok := mt[o.Path]
+		if !ok {
+			m = Miss{Path: o.Path}
+		}
+		m.merge(o)
+		mt[o.Path] = m
+	}
+}
+
+func (mt missTable) list() []Miss {
+	ms := make([]Miss, 0, len(mt))
+	for _, m := range mt {
+		ms = append(ms, m)
+	}
+	return ms
+}
+
+func (mt missTable) ignore(path string, ignore bool) {
+	m, ok := mt[path]
+	if !ok {
+		m = Miss{Path: path}
+	}
+	m.Ignored = ignore
+	mt[path] = m
+}
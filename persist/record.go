@@ -0,0 +1,187 @@
+package persist
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Records start with a zero byte followed by the format version. A gob
+// stream never starts with a zero byte because every gob message is
+// prefixed with its non-zero length, so anything else is a legacy gob record.
+const (
+	recordMarker  = 0x00
+	recordVersion = 4
+)
+
+// Bits of the record flags
+const flagGenerated = 1 << 0
+
+var errShortRecord = errors.New("truncated record")
+
+// encode writes s in the current record format:
+//
+//	marker | version | Path | Site | Count
+//	| Created | Updated | LastVisited | Creator | Description | len(Tags) | Tags...
+//	| Forward.Path | Forward.Query | Forward.Fragment | Forward.Params | flags
+//
+// Strings are uvarint length prefixed, Count is a varint and times are
+// varint Unix nanoseconds with zero meaning unset. Flags is a uvarint with
+// flagGenerated set for Generated links. Version 1 records end after Count,
+// version 2 records after Tags and version 3 records after Forward.
+func (s *Short) encode() []byte {
+	b := make([]byte, 0, 64+len(s.Path)+len(s.Site)+len(s.Creator)+len(s.Description))
+	b = append(b, recordMarker, recordVersion)
+	b = appendString(b, s.Path)
+	b = appendString(b, s.Site)
+	b = appendVarint(b, int64(s.Count))
+	b = appendTime(b, s.Created)
+	b = appendTime(b, s.Updated)
+	b = appendTime(b, s.LastVisited)
+	b = appendString(b, s.Creator)
+	b = appendString(b, s.Description)
+	b = appendUvarint(b, uint64(len(s.Tags)))
+	for _, t := range s.Tags {
+		b = appendString(b, t)
+	}
+	b = appendString(b, s.Forward.Path)
+	b = appendString(b, s.Forward.Query)
+	b = appendString(b, s.Forward.Fragment)
+	b = appendString(b, s.Forward.Params)
+	var flags uint64
+	if s.Generated {
+		flags |= flagGenerated
+	}
+	b = appendUvarint(b, flags)
+	return b
+}
+
+// decode reads a record written by encode, by an earlier record version or
+// by the legacy gob encoder
+func decode(d []byte) (*Short, error) {
+	if isLegacy(d) {
+		return gobDecode(d)
+	}
+	if len(d) < 2 {
+		return nil, errShortRecord
+	}
+	version := d[1]
+	if version < 1 || version > recordVersion {
+		return nil, errors.New("unknown record version")
+	}
+	var s Short
+	var err error
+	r := d[2:]
+	if s.Path, r, err = readString(r); err != nil {
+		return nil, err
+	}
+	if s.Site, r, err = readString(r); err != nil {
+		return nil, err
+	}
+	c, r, err := readVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	s.Count = int(c)
+	if version < 2 {
+		return &s, nil
+	}
+	if s.Created, r, err = readTime(r); err != nil {
+		return nil, err
+	}
+	if s.Updated, r, err = readTime(r); err != nil {
+		return nil, err
+	}
+	if s.LastVisited, r, err = readTime(r); err != nil {
+		return nil, err
+	}
+	if s.Creator, r, err = readString(r); err != nil {
+		return nil, err
+	}
+	if s.Description, r, err = readString(r); err != nil {
+		return nil, err
+	}
+	n, l := binary.Uvarint(r)
+	if l <= 0 || n > uint64(len(r)) {
+		return nil, errShortRecord
+	}
+	r = r[l:]
+	for i := uint64(0); i < n; i++ {
+		var t string
+		if t, r, err = readString(r); err != nil {
+			return nil, err
+		}
+		s.Tags = append(s.Tags, t)
+	}
+	if version < 3 {
+		return &s, nil
+	}
+	for _, f := range []*string{&s.Forward.Path, &s.Forward.Query, &s.Forward.Fragment, &s.Forward.Params} {
+		if *f, r, err = readString(r); err != nil {
+			return nil, err
+		}
+	}
+	if version < 4 {
+		return &s, nil
+	}
+	flags, l := binary.Uvarint(r)
+	if l <= 0 {
+		return nil, errShortRecord
+	}
+	s.Generated = flags&flagGenerated != 0
+	return &s, nil
+}
+
+// isLegacy reports whether d was written by the gob encoder
+func isLegacy(d []byte) bool {
+	return len(d) > 0 && d[0] != recordMarker
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return appendVarint(b, 0)
+	}
+	return appendVarint(b, t.UnixNano())
+}
+
+func readVarint(b []byte) (int64, []byte, error) {
+	v, n := binary.Varint(b)
+	if n <= 0 {
+		return 0, nil, errShortRecord
+	}
+	return v, b[n:], nil
+}
+
+func readString(b []byte) (string, []byte, error) {
+	l, n := binary.Uvarint(b)
+	if n <= 0 || uint64(len(b)-n) < l {
+		return "", nil, errShortRecord
+	}
+	b = b[n:]
+	return string(b[:l]), b[l:], nil
+}
+
+func readTime(b []byte) (time.Time, []byte, error) {
+	v, b, err := readVarint(b)
+	if err != nil || v == 0 {
+		return time.Time{}, b, err
+	}
+	return time.Unix(0, v), b, nil
+}
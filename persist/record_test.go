@@ -0,0 +1,132 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+var benchShort = Short{
+	Path:        "nyt",
+	Site:        "https://www.nytimes.com/section/{1}",
+	Count:       12345,
+	Created:     time.Date(2020, 5, 1, 9, 30, 0, 0, time.UTC),
+	Updated:     time.Date(2020, 6, 2, 10, 0, 0, 0, time.UTC),
+	LastVisited: time.Date(2020, 7, 3, 11, 15, 0, 0, time.UTC),
+	Creator:     "alice",
+	Description: "The New York Times",
+	Tags:        []string{"news", "daily"},
+	Forward:     Forward{Path: PathAppend, Query: QueryMerge, Params: "utm_source=map"},
+}
+
+// gobEncode writes s the way records were stored before the versioned
+// record format
+func gobEncode(tb testing.TB, s Short) []byte {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestMigrate checks that a record stored with gob reads back the same
+// before and after Migrate rewrites it in the versioned format
+func TestMigrate(t *testing.T) {
+	store, err := OpenBadger("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	db := store.(*database)
+	err = db.DB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(benchShort.Path), gobEncode(t, benchShort))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := func() []byte {
+		t.Helper()
+		var v []byte
+		err := db.DB.View(func(txn *badger.Txn) error {
+			i, err := txn.Get([]byte(benchShort.Path))
+			if err != nil {
+				return err
+			}
+			v, err = i.ValueCopy(nil)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	check := func(when string) {
+		t.Helper()
+		s, err := db.Get(benchShort.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := *s
+		got.Created, got.Updated, got.LastVisited = got.Created.UTC(), got.Updated.UTC(), got.LastVisited.UTC()
+		if !reflect.DeepEqual(got, benchShort) {
+			t.Errorf("%v: read %+v, want %+v", when, got, benchShort)
+		}
+	}
+	if !isLegacy(raw()) {
+		t.Fatal("the gob record is not taken for a legacy one")
+	}
+	check("before migrating")
+
+	n, err := db.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("migrated %v records, want 1", n)
+	}
+	if isLegacy(raw()) {
+		t.Error("the record is still in the gob format")
+	}
+	check("after migrating")
+	if n, err := db.Migrate(); err != nil || n != 0 {
+		t.Errorf("second Migrate = %v, %v, want nothing left to migrate", n, err)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchShort.encode()
+		}
+	})
+	b.Run("gob", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			gobEncode(b, benchShort)
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{"binary", benchShort.encode()},
+		{"gob", gobEncode(b, benchShort)},
+	} {
+		b.Run(f.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := decode(f.data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package persist
+
+import (
+	"sort"
+	"time"
+)
+
+// Bucket spans
+const (
+	Hour = "hour"
+	Day  = "day"
+)
+
+// Visit counts the redirects through one link in one hour that came from the
+// same referrer host with the same class of user agent
+type Visit struct {
+	Path     string
+	Time     time.Time
+	Referrer string
+	Agent    string
+	Count    int
+}
+
+// Bucket holds the visits to a link in one hour or day
+type Bucket struct {
+	Span      string
+	Start     time.Time
+	Count     int
+	Referrers map[string]int
+	Agents    map[string]int
+}
+
+// Stats is the visit history of a single link, oldest bucket first
+type Stats struct {
+	Path   string
+	Hourly []Bucket
+	Daily  []Bucket
+}
+
+// spanStart returns the start of the bucket of the given span holding t
+func spanStart(span string, t time.Time) time.Time {
+	t = t.UTC()
+	if span == Day {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+func newBucket(span string, t time.Time) *Bucket {
+	return &Bucket{
+		Span:      span,
+		Start:     spanStart(span, t),
+		Referrers: make(map[string]int),
+		Agents:    make(map[string]int),
+	}
+}
+
+// add counts v in b
+func (b *Bucket) add(v Visit) {
+	b.Count += v.Count
+	if v.Referrer != "" {
+		b.Referrers[v.Referrer] += v.Count
+	}
+	if v.Agent != "" {
+		b.Agents[v.Agent] += v.Count
+	}
+}
+
+// merge adds the counts of o to b
+func (b *Bucket) merge(o *Bucket) {
+	b.Count += o.Count
+	for k, v := range o.Referrers {
+		b.Referrers[k] += v
+	}
+	for k, v := range o.Agents {
+		b.Agents[k] += v
+	}
+}
+
+// sortBuckets splits buckets into the hourly and daily series of s
+func (s *Stats) sortBuckets(buckets []*Bucket) {
+	for _, b := range buckets {
+		if b.Span == Day {
+			s.Daily = append(s.Daily, *b)
+		} else {
+			s.Hourly = append(s.Hourly, *b)
+		}
+	}
+	sort.Slice(s.Hourly, func(i, j int) bool { return s.Hourly[i].Start.Before(s.Hourly[j].Start) })
+	sort.Slice(s.Daily, func(i, j int) bool { return s.Daily[i].Start.Before(s.Daily[j].Start) })
+}
+
+// statsTable keeps visit buckets in memory for the memory and json stores,
+// keyed by path and then by bucket key
+type statsTable map[string]map[string]*Bucket
+
+func bucketKey(span string, start time.Time) string {
+	return span + "/" + start.Format(time.RFC3339)
+}
+
+func (st statsTable) record(vs []Visit) {
+	for _, v := range vs {
+		start := spanStart(Hour, v.Time)
+		k := bucketKey(Hour, start)
+		if st[v.Path] == nil {
+			st[v.Path] = make(map[string]*Bucket)
+		}
+		b, ok := st[v.Path][k]
+		if !ok {
+			b = newBucket(Hour, start)
+			st[v.Path][k] = b
+		}
+		b.add(v)
+	}
+}
+
+func (st statsTable) stats(path string) *Stats {
+	s := &Stats{Path: path}
+	var buckets []*Bucket
+	for _, b := range st[path] {
+		buckets = append(buckets, b)
+	}
+	s.sortBuckets(buckets)
+	return s
+}
+
+// rollup merges hourly buckets that started before cutoff into daily buckets
+func (st statsTable) rollup(cutoff time.Time) {
+	for _, buckets := range st {
+		for k, b := range buckets {
+			if b.Span != Hour || !b.Start.Before(cutoff) {
+				continue
+			}
+			dk := bucketKey(Day, spanStart(Day, b.Start))
+			d, ok := buckets[dk]
+			if !ok {
+				d = newBucket(Day, b.Start)
+				buckets[dk] = d
+			}
+			d.merge(b)
+			delete(buckets, k)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package persist
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Store is implemented by every storage backend that can hold the link table
+type Store interface {
+	// Get single key from the store. It returns ErrNotFound when k is
+	// missing and a CorruptError when its record cannot be decoded.
+	Get(k string) (*Short, error)
+	// Save single key to the store
+	Save(s Short) error
+	// SaveMap saves every value in m under its key. Values with a zero
+	// Count keep the Count and LastVisited stored under their key, read
+	// along with the write so no concurrent Incr is lost.
+	SaveMap(m map[string]Short) error
+	// GetAll returns every link in the store keyed by path. Records that
+	// cannot be decoded are left out and reported in a CorruptError
+	// returned along with the rest.
+	GetAll() (map[string]Short, error)
+	// Incr atomically adds n to the visit count of key k, the latest of
+	// which happened at last. It returns ErrNotFound when k is missing.
+	Incr(k string, n int, last time.Time) error
+	// RecordVisits adds visits to the hourly buckets of their links
+	RecordVisits(vs []Visit) error
+	// Stats returns the visit history of the link at path
+	Stats(path string) (*Stats, error)
+	// Rollup merges hourly buckets that started before cutoff into daily
+	// buckets
+	Rollup(cutoff time.Time) error
+	// RecordMisses adds the requests counted in ms to the misses recorded
+	// under their paths
+	RecordMisses(ms []Miss) error
+	// Misses returns every recorded miss
+	Misses() ([]Miss, error)
+	// IgnoreMiss sets whether the miss at path is left out of rankings,
+	// recording it if needed
+	IgnoreMiss(path string, ignore bool) error
+	// DeleteMiss forgets the miss at path
+	DeleteMiss(path string) error
+	// NextSequence returns a number never returned before, counting up
+	// from 1, to number generated short codes
+	NextSequence() (uint64, error)
+	// Delete removes a single key and its visit history from the store. It
+	// returns ErrNotFound when k is missing.
+	Delete(k string) error
+	// Check walks every record and reports the ones that cannot be decoded
+	// or are inconsistent
+	Check() ([]Problem, error)
+	// Quarantine moves the records under keys out of the link table so
+	// they no longer take part in lookups
+	Quarantine(keys []string) error
+	// Backup writes a snapshot of the whole store to w
+	Backup(w io.Writer) error
+	// Restore replaces the contents of the store with a snapshot written
+	// by Backup on the same kind of backend. It is safe to call while the
+	// store is in use.
+	Restore(r io.Reader) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// Migrator is implemented by stores that may hold records written in an
+// older format
+type Migrator interface {
+	// Migrate rewrites old records in the current format and returns how
+	// many were converted
+	Migrate() (int, error)
+}
+
+// Backends lists the names accepted by Open
+var Backends = []string{"badger", "memory", "json"}
+
+// Open returns the store backend registered under name. Location is the
+// badger directory or the JSON file and is ignored by the memory backend.
+func Open(name, location string) (Store, error) {
+	switch name {
+	case "badger":
+		return OpenBadger(location)
+	case "memory":
+		return NewMemory(), nil
+	case "json":
+		return OpenJSON(location)
+	}
+	return nil, fmt.Errorf("unknown store backend %q", name)
+}
+
+// keepVisits carries the visits of old over to s unless s sets its own
+func (s *Short) keepVisits(old Short) {
+	if s.Count == 0 {
+		s.Count, s.LastVisited = old.Count, old.LastVisited
+	}
+}
+
+// visit adds n visits to s, the latest of which happened at last
+func (s *Short) visit(n int, last time.Time) {
+	s.Count += n
+	if last.After(s.LastVisited) {
+		s.LastVisited = last
+	}
+}
@@ -0,0 +1,145 @@
+package urlshort
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// prefixRe matches the page prefixes Options.Prefix accepts
+var prefixRe = regexp.MustCompile(`^[A-Za-z0-9._~-]*$`)
+
+// Check reports an unknown feature, bad code settings, a prefix or base
+// path that cannot be served, or an embedded template that does not parse
+func (o Options) Check() error {
+	for _, list := range [][]string{o.Disable, o.Enable} {
+		for _, f := range list {
+			if !contains(Features, f) {
+				return fmt.Errorf("unknown feature %q", f)
+			}
+		}
+	}
+	if err := o.Codes.Check(); err != nil {
+		return err
+	}
+	if !prefixRe.MatchString(o.Prefix) || o.Prefix == "." || o.Prefix == ".." {
+		return fmt.Errorf("bad page prefix %q: use a single path segment", o.Prefix)
+	}
+	if o.BasePath != "" && !strings.HasPrefix(o.BasePath, "/") {
+		return fmt.Errorf("bad base path %q: it must start with a slash", o.BasePath)
+	}
+	if _, err := url.Parse(o.BasePath); err != nil {
+		return fmt.Errorf("bad base path %q: %v", o.BasePath, err)
+	}
+	for _, name := range []string{"list", "import", "conflicts", "notfound", "misses"} {
+		if _, err := parseTemplate(name, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalize drops the slashes around Prefix and after BasePath
+func (o Options) normalize() Options {
+	o.Prefix = strings.Trim(o.Prefix, "/")
+	o.BasePath = strings.TrimRight(o.BasePath, "/")
+	return o
+}
+
+// page returns the URL path of the built-in page p, such as "list"
+func (o Options) page(p string) string {
+	root := o.BasePath
+	if o.Prefix != "" {
+		root += "/" + o.Prefix
+	}
+	return root + "/" + p
+}
+
+// link returns the URL path of the link at path
+func (o Options) link(path string) string {
+	return o.BasePath + "/" + (&url.URL{Path: path}).EscapedPath()
+}
+
+// Reserved returns the link paths taken by the built-in pages enabled by o.
+// A name ending in a slash also takes every path below it.
+func (o Options) Reserved() []string {
+	o = o.normalize()
+	if o.Prefix != "" {
+		return []string{o.Prefix + "/"}
+	}
+	var names []string
+	for _, r := range routes(nil, o) {
+		names = append(names, r.pattern)
+	}
+	return names
+}
+
+// isReserved reports whether path is taken by one of names
+func isReserved(names []string, path string) bool {
+	for _, n := range names {
+		if path == strings.TrimSuffix(n, "/") || strings.HasSuffix(n, "/") && strings.HasPrefix(path, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reserve keeps links from being saved under names, as returned by
+// Options.Reserved, and lets the pages behind them answer first
+func (t *Table) Reserve(names []string) {
+	t.reserved.Store(names)
+}
+
+// Reserved returns the names passed to Reserve
+func (t *Table) Reserved() []string {
+	names, _ := t.reserved.Load().([]string)
+	return names
+}
+
+func (t *Table) isReserved(path string) bool {
+	return isReserved(t.Reserved(), path)
+}
+
+// checkReserved reports a link path taken by a built-in page
+func (t *Table) checkReserved(path string) error {
+	if t.isReserved(path) {
+		return fmt.Errorf("/%v is reserved for a built-in page", path)
+	}
+	return nil
+}
+
+// stripPath returns r with prefix removed from its path, and whether the
+// path started with it
+func stripPath(r *http.Request, prefix string) (*http.Request, bool) {
+	p := strings.TrimPrefix(r.URL.Path, prefix)
+	if p == r.URL.Path || p != "" && p[0] != '/' {
+		return r, false
+	}
+	if p == "" {
+		p = "/"
+	}
+	r2 := new(http.Request)
+	*r2 = *r
+	u := *r.URL
+	u.Path = p
+	if raw := strings.TrimPrefix(u.RawPath, prefix); raw != u.RawPath {
+		u.RawPath = raw
+	} else {
+		u.RawPath = ""
+	}
+	r2.URL = &u
+	return r2, true
+}
+
+// mount removes BasePath from requests before h sees them. Requests
+// without it are served as they are, for proxies that strip it themselves.
+func (o Options) mount(h http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if o.BasePath != "" {
+			r, _ = stripPath(r, o.BasePath)
+		}
+		h.ServeHTTP(w, r)
+	}
+}
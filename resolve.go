@@ -0,0 +1,206 @@
+package urlshort
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"urlshort/persist"
+)
+
+// paramRe matches the placeholders of a templated target: {1} and {2} for
+// positional arguments, {*} for the rest of the path and {name} for named
+// ones, each optionally followed by :default
+var paramRe = regexp.MustCompile(`\{(\*|[0-9]+|[A-Za-z_][A-Za-z0-9_-]*)(?::([^{}]*))?\}`)
+
+// hasParams reports whether target takes arguments
+func hasParams(target string) bool {
+	return paramRe.MatchString(target)
+}
+
+// resolve finds the link serving the request URL u and the URL to redirect
+// to. It is the one resolution step shared by every handler. An exact match
+// wins, then the first matching rewrite rule; otherwise the longest leading
+// run of path segments naming a link takes the segments that follow, either
+// as arguments of a templated target or, as the link's Forward rules say, as
+// extra path. The request query and the link's static parameters and
+// fragment are then applied to the target.
+func resolve(lookup func(string) (persist.Short, bool), rules []rule, u *url.URL) (persist.Short, string, bool) {
+	query := u.Query()
+	urlpath := strings.TrimLeft(u.Path, "/")
+	if isRule(urlpath) {
+		return persist.Short{}, "", false
+	}
+	if s, ok := lookup(urlpath); ok {
+		return s, forward(s, nil, false, query), true
+	}
+	if s, target, ok := matchRule(rules, urlpath); ok {
+		rewritten := s
+		rewritten.Site = target
+		return s, forward(rewritten, nil, false, query), true
+	}
+	var segs []string
+	for _, raw := range strings.Split(strings.TrimLeft(u.EscapedPath(), "/"), "/") {
+		seg, err := url.PathUnescape(raw)
+		if err != nil {
+			return persist.Short{}, "", false
+		}
+		segs = append(segs, seg)
+	}
+	for n := len(segs) - 1; n > 0; n-- {
+		s, ok := lookup(strings.Join(segs[:n], "/"))
+		if !ok || isRule(s.Path) || !hasParams(s.Site) && s.Forward.Path == persist.PathExact {
+			continue
+		}
+		return s, forward(s, nonEmpty(segs[n:]), segs[len(segs)-1] == "", query), true
+	}
+	return persist.Short{}, "", false
+}
+
+// forward builds the redirect URL for link s from the extra path segments
+// rest, whether the request path ended in a slash, and the request query
+func forward(s persist.Short, rest []string, slash bool, query url.Values) string {
+	f := s.Forward
+	target := s.Site
+	if hasParams(target) {
+		target = expand(target, rest, query)
+		// Query parameters filling named placeholders are not forwarded
+		for _, m := range paramRe.FindAllStringSubmatch(s.Site, -1) {
+			query.Del(m[1])
+		}
+		rest = nil
+	}
+	if f.Path == persist.PathDrop {
+		rest = nil
+	}
+	if len(rest) == 0 && len(query) == 0 && f.Params == "" && f.Fragment == "" {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	if len(rest) > 0 {
+		esc := make([]string, len(rest))
+		for i, a := range rest {
+			esc[i] = url.PathEscape(a)
+		}
+		extra, rawExtra := strings.Join(rest, "/"), strings.Join(esc, "/")
+		if slash {
+			extra, rawExtra = extra+"/", rawExtra+"/"
+		}
+		u.RawPath = strings.TrimRight(u.EscapedPath(), "/") + "/" + rawExtra
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + extra
+	}
+
+	params, _ := url.ParseQuery(f.Params)
+	if f.Query == persist.QueryDrop {
+		query = nil
+	}
+	if len(query) > 0 || len(params) > 0 {
+		q := u.Query()
+		if f.Query == persist.QueryReplace && len(query) > 0 {
+			q = url.Values{}
+		}
+		for k, v := range query {
+			q[k] = v
+		}
+		for k, v := range params {
+			q[k] = v
+		}
+		u.RawQuery = q.Encode()
+	}
+	if f.Fragment != "" {
+		u.Fragment = f.Fragment
+	}
+	return u.String()
+}
+
+// nonEmpty drops the empty segments left by doubled or trailing slashes
+func nonEmpty(segs []string) []string {
+	var a []string
+	for _, s := range segs {
+		if s != "" {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// expand fills the placeholders of target. Named placeholders take a query
+// parameter of the same name, or else the positional arguments in the order
+// the names first appear. Placeholders without a value are replaced by their
+// default, or removed along with a slash that follows them in the path.
+// Values are escaped for the part of the URL they land
+// in; defaults are used as written.
+func expand(target string, args []string, query url.Values) string {
+	if !hasParams(target) {
+		return target
+	}
+	named := make(map[string]int) // name -> index in args, -1 when none is left
+	next := 0
+	var b strings.Builder
+	last := 0
+	for _, m := range paramRe.FindAllStringSubmatchIndex(target, -1) {
+		b.WriteString(target[last:m[0]])
+		last = m[1]
+		name := target[m[2]:m[3]]
+		inQuery := strings.ContainsAny(target[:m[0]], "?#")
+
+		v, ok := "", false
+		switch n, err := strconv.Atoi(name); {
+		case name == "*":
+			if ok = len(args) > 0; ok {
+				v = escapeRest(args, inQuery)
+			}
+		case err == nil:
+			if ok = n >= 1 && n <= len(args); ok {
+				v = escapeArg(args[n-1], inQuery)
+			}
+		case query.Get(name) != "":
+			v, ok = escapeArg(query.Get(name), inQuery), true
+		default:
+			i, seen := named[name]
+			if !seen {
+				i = -1
+				if next < len(args) {
+					i = next
+					next++
+				}
+				named[name] = i
+			}
+			if ok = i >= 0; ok {
+				v = escapeArg(args[i], inQuery)
+			}
+		}
+		if !ok && m[4] >= 0 {
+			v = target[m[4]:m[5]]
+		}
+		if v == "" && !inQuery && strings.HasPrefix(target[last:], "/") {
+			last++
+		}
+		b.WriteString(v)
+	}
+	b.WriteString(target[last:])
+	return b.String()
+}
+
+func escapeArg(a string, inQuery bool) string {
+	if inQuery {
+		return url.QueryEscape(a)
+	}
+	return url.PathEscape(a)
+}
+
+// escapeRest escapes the remaining path, keeping its slashes in a path
+func escapeRest(args []string, inQuery bool) string {
+	if inQuery {
+		return url.QueryEscape(strings.Join(args, "/"))
+	}
+	esc := make([]string, len(args))
+	for i, a := range args {
+		esc[i] = url.PathEscape(a)
+	}
+	return strings.Join(esc, "/")
+}
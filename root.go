@@ -0,0 +1,94 @@
+package urlshort
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Root is the root handler of the server. Reloads build a complete new
+// handler and swap it in atomically, so requests are never served from a
+// half-built one and reloading never races with serving.
+type Root struct {
+	handler atomic.Value // rootHandler
+	mu      sync.Mutex   // serialises reloads
+}
+
+// rootHandler boxes the handler so atomic.Value always holds one type
+type rootHandler struct {
+	http.Handler
+}
+
+// NewRoot returns a Root serving h
+func NewRoot(h http.Handler) *Root {
+	r := &Root{}
+	r.handler.Store(rootHandler{h})
+	return r
+}
+
+func (r *Root) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.Load().(rootHandler).ServeHTTP(w, req)
+}
+
+// Reload calls build and serves the handler it returns. If build fails the
+// current handler keeps serving and the error is returned.
+func (r *Root) Reload(build func() (http.Handler, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, err := build()
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return fmt.Errorf("reload built no handler")
+	}
+	r.handler.Store(rootHandler{h})
+	return nil
+}
+
+// NewHandler is SetHandler that checks opts and the embedded templates
+// first, for use with Root.Reload
+func NewHandler(table *Table, opts Options) (http.Handler, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	return SetHandler(table, opts), nil
+}
+
+// ReloadResult reports the outcome of a reload
+type ReloadResult struct {
+	OK      bool
+	Error   string   `json:",omitempty"`
+	Links   int      // links served after the reload
+	Synced  int      // changes made by syncing link files
+	Changed []string `json:",omitempty"` // settings that changed
+	Restart []string `json:",omitempty"` // changed settings that need a restart
+}
+
+// reloadHandler runs opts.Reload and returns its result
+func reloadHandler(reload func() ReloadResult) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		res := reload()
+		w.Header().Set("Content-Type", "application/json")
+		if !res.OK {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
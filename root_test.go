@@ -0,0 +1,112 @@
+package urlshort
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"urlshort/persist"
+)
+
+// reload does what the server does on a reload: check the synced links and
+// the handler first, then put both to use
+func reload(root *Root, table *Table, sources []Source, opts Options) error {
+	return root.Reload(func() (http.Handler, error) {
+		if err := opts.Check(); err != nil {
+			return nil, err
+		}
+		plan, err := table.PlanSync(sources, SyncUnion, opts.Reserved())
+		if err != nil {
+			return nil, err
+		}
+		if err := table.ApplySync(plan); err != nil {
+			return nil, err
+		}
+		return NewHandler(table, opts)
+	})
+}
+
+// TestReloadUnderLoad reloads good and broken link files while requests are
+// served. Run with -race.
+func TestReloadUnderLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "map-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "links.yaml")
+	good := []byte("- path: nyt\n  url: https://www.nytimes.com\n- path: go\n  url: https://golang.org\n")
+	broken := []byte("- path: nyt\n  url: https://www.nytimes.com\n- path: bad\n  url: '#'\n")
+	if err := ioutil.WriteFile(file, good, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources := []Source{{Name: "personal", File: file}}
+
+	store := persist.NewMemory()
+	table, err := NewTable(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{}
+	table.Reserve(opts.Reserved())
+	if _, err := table.SyncSources(sources, SyncUnion, false); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHandler(table, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewRoot(h)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for _, p := range []string{"/nyt", "/go"} {
+					w := httptest.NewRecorder()
+					root.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+					if w.Code != http.StatusFound {
+						t.Errorf("GET %v = %v during reload, want %v", p, w.Code, http.StatusFound)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := ioutil.WriteFile(file, broken, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reload(root, table, sources, opts); err == nil {
+			t.Fatal("reload of a broken link file succeeded")
+		}
+		if _, err := store.Get("bad"); err != persist.ErrNotFound {
+			t.Fatalf("failed reload wrote to the store: %v", err)
+		}
+		if _, ok := table.Lookup("bad"); ok {
+			t.Fatal("failed reload changed the links served")
+		}
+		if err := ioutil.WriteFile(file, good, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reload(root, table, sources, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	table.Close()
+}
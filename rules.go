@@ -0,0 +1,152 @@
+package urlshort
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"urlshort/persist"
+)
+
+// Links whose path starts with ^ are rewrite rules: the path is a regular
+// expression matched against the whole request path, and $1, ${name} in the
+// target are replaced by its groups. Rules are tried after exact matches
+// miss and before prefix matches, oldest first, ties broken by pattern.
+const rulePrefix = "^"
+
+// Limits keeping rules cheap to compile and run. Go regular expressions run
+// in linear time, so size is what needs bounding.
+const (
+	maxRuleLen   = 256
+	maxRuleInsts = 2000
+)
+
+// isRule reports whether the link stored under path is a rewrite rule
+func isRule(path string) bool {
+	return strings.HasPrefix(path, rulePrefix)
+}
+
+// rule is a compiled rewrite rule
+type rule struct {
+	link persist.Short
+	re   *regexp.Regexp
+}
+
+var groupRe = regexp.MustCompile(`\$(\$|[0-9]+|\{[A-Za-z0-9_]+\})`)
+
+// compileRule checks that the rule stored in s is safe to serve and
+// compiles it
+func compileRule(s persist.Short) (*regexp.Regexp, error) {
+	if len(s.Path) > maxRuleLen {
+		return nil, fmt.Errorf("rule %.20q...: longer than %v characters", s.Path, maxRuleLen)
+	}
+	re, err := regexp.Compile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %v", s.Path, err)
+	}
+	parsed, err := syntax.Parse(s.Path, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %v", s.Path, err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil || len(prog.Inst) > maxRuleInsts {
+		return nil, fmt.Errorf("rule %q: too complex", s.Path)
+	}
+	// Built-in pages answer before rules are tried, only the root is left
+	// to guard
+	if re.MatchString("") {
+		return nil, fmt.Errorf("rule %q: matches %q, make it more specific", s.Path, "/")
+	}
+	for _, m := range groupRe.FindAllStringSubmatch(s.Site, -1) {
+		g := strings.Trim(m[1], "{}")
+		if g == "$" {
+			continue
+		}
+		if n, err := strconv.Atoi(g); err == nil && n > re.NumSubexp() || err != nil && subexpIndex(re, g) < 0 {
+			return nil, fmt.Errorf("rule %q: the target uses $%v, which the pattern does not capture", s.Path, m[1])
+		}
+	}
+	return re, nil
+}
+
+// checkRule reports why the rule in s cannot be saved, if it is a rule
+func checkRule(s persist.Short) error {
+	if !isRule(s.Path) {
+		return nil
+	}
+	_, err := compileRule(s)
+	return err
+}
+
+// compileRules compiles the rules among links in evaluation order. Rules
+// that do not compile are logged and left out.
+func compileRules(links map[string]persist.Short) []rule {
+	var rules []rule
+	for k, s := range links {
+		if !isRule(k) {
+			continue
+		}
+		re, err := compileRule(s)
+		if err != nil {
+			log.Printf("Skipping %v", err)
+			continue
+		}
+		rules = append(rules, rule{s, re})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i].link, rules[j].link
+		if !a.Created.Equal(b.Created) {
+			return a.Created.Before(b.Created)
+		}
+		return a.Path < b.Path
+	})
+	return rules
+}
+
+// subexpIndex returns the index of the group called name, or -1
+func subexpIndex(re *regexp.Regexp, name string) int {
+	for i, n := range re.SubexpNames() {
+		if n == name && name != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchRule returns the first rule matching urlpath and its target with the
+// groups filled in and escaped for the part of the URL they land in
+func matchRule(rules []rule, urlpath string) (persist.Short, string, bool) {
+	for _, r := range rules {
+		m := r.re.FindStringSubmatchIndex(urlpath)
+		if m == nil {
+			continue
+		}
+		target := r.link.Site
+		var b strings.Builder
+		last := 0
+		for _, g := range groupRe.FindAllStringSubmatchIndex(target, -1) {
+			b.WriteString(target[last:g[0]])
+			last = g[1]
+			name := strings.Trim(target[g[2]:g[3]], "{}")
+			if name == "$" {
+				b.WriteString("$")
+				continue
+			}
+			n, err := strconv.Atoi(name)
+			if err != nil {
+				n = subexpIndex(r.re, name)
+			}
+			if n < 0 || 2*n+1 >= len(m) || m[2*n] < 0 {
+				continue
+			}
+			b.WriteString(escapeArg(urlpath[m[2*n]:m[2*n+1]], strings.ContainsAny(target[:g[0]], "?#")))
+		}
+		b.WriteString(target[last:])
+		return r.link, b.String(), true
+	}
+	return persist.Short{}, "", false
+}
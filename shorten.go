@@ -0,0 +1,189 @@
+package urlshort
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"urlshort/persist"
+)
+
+// Short codes are written in base62
+const codeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Limits of generated short codes
+const (
+	DefaultCodeLength = 5
+	MaxCodeLength     = 10 // 62^10 still fits in a uint64
+	maxShortenURL     = 2048
+	maxCodeAttempts   = 100
+)
+
+// codeFactor and codeOffset scramble sequence numbers into codes. The factor
+// is a prime other than 2 and 31, so multiplying by it is a permutation of
+// the numbers below any power of 62.
+const (
+	codeFactor = 1580030173
+	codeOffset = 916132831
+)
+
+// CodeOptions configures the short codes generated by Shorten
+type CodeOptions struct {
+	// Length is the least number of characters in a code, codes growing
+	// longer once shorter ones run out. Zero means DefaultCodeLength.
+	Length int
+	// Obfuscate scrambles codes so they do not reveal how many were
+	// generated or which one comes next
+	Obfuscate bool
+}
+
+// Check reports a length out of range
+func (o CodeOptions) Check() error {
+	if o.Length < 0 || o.Length > MaxCodeLength {
+		return fmt.Errorf("code length must be between 1 and %v", MaxCodeLength)
+	}
+	return nil
+}
+
+// code turns sequence number n into a short code
+func (o CodeOptions) code(n uint64) string {
+	length := o.Length
+	if length == 0 {
+		length = DefaultCodeLength
+	}
+	space := uint64(1)
+	for i := 0; i < length; i++ {
+		space *= uint64(len(codeAlphabet))
+	}
+	for n >= space && length < MaxCodeLength {
+		space *= uint64(len(codeAlphabet))
+		length++
+	}
+	if o.Obfuscate {
+		hi, lo := bits.Mul64(n, codeFactor)
+		lo, carry := bits.Add64(lo, codeOffset, 0)
+		n = bits.Rem64(hi+carry, lo, space)
+	}
+	b := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		b[i] = codeAlphabet[n%uint64(len(codeAlphabet))]
+		n /= uint64(len(codeAlphabet))
+	}
+	return string(b)
+}
+
+// Shorten returns the generated link to target, creating one under a new
+// short code unless target was shortened before. The bool reports whether
+// the link was created.
+func (t *Table) Shorten(target string, opts CodeOptions) (persist.Short, bool, error) {
+	if err := checkShortenURL(target); err != nil {
+		return persist.Short{}, false, err
+	}
+	// Braces would be taken for placeholders
+	target = strings.NewReplacer("{", "%7B", "}", "%7D").Replace(target)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	links := t.snapshot()
+	for _, s := range links {
+		if s.Generated && s.Site == target {
+			return s, false, nil
+		}
+	}
+	for i := 0; i < maxCodeAttempts; i++ {
+		n, err := t.store.NextSequence()
+		if err != nil {
+			return persist.Short{}, false, err
+		}
+		code := opts.code(n)
+		if _, taken := links[code]; taken || t.isReserved(code) {
+			continue
+		}
+		s := persist.Short{Path: code, Site: target, Generated: true}
+		if err := t.saveAll([]persist.Short{s}); err != nil {
+			return persist.Short{}, false, err
+		}
+		s, _ = t.Lookup(code)
+		return s, true, nil
+	}
+	return persist.Short{}, false, errors.New("no free short code left, raise the code length")
+}
+
+// checkShortenURL reports a target Shorten does not take
+func checkShortenURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", target)
+	}
+	if len(target) > maxShortenURL {
+		return fmt.Errorf("URLs to shorten are limited to %v characters", maxShortenURL)
+	}
+	return nil
+}
+
+// shortenResult is the answer to POST /shorten
+type shortenResult struct {
+	Code     string `json:"code"`
+	ShortURL string `json:"short_url"`
+	URL      string `json:"url"`
+	Created  bool   `json:"created"`
+}
+
+// shortenHandler generates a short code for the url posted as a form value
+// or in a JSON object
+func shortenHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			apiRespond(w, http.StatusMethodNotAllowed, apiErrorf(http.StatusMethodNotAllowed, "%v is not allowed here", r.Method))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxAPIBody)
+		var req struct {
+			URL string `json:"url"`
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				apiRespond(w, http.StatusBadRequest, apiErrorf(http.StatusBadRequest, "cannot read the request: %v", err))
+				return
+			}
+		} else {
+			req.URL = r.FormValue("url")
+		}
+		target := strings.TrimSpace(req.URL)
+		if err := checkShortenURL(target); err != nil {
+			apiRespond(w, http.StatusUnprocessableEntity, apiErrorf(http.StatusUnprocessableEntity, "%v", err))
+			return
+		}
+		// The URL is fine, whatever fails now is on the server
+		s, created, err := table.Shorten(target, opts.Codes)
+		if err != nil {
+			log.Printf("Shorten failed: %v", err)
+			apiRespond(w, http.StatusInternalServerError, apiErrorf(http.StatusInternalServerError, "%v", err))
+			return
+		}
+		if created {
+			log.Printf("Shortened %v -> %v", s.Path, s.Site)
+		}
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		res := shortenResult{
+			Code:     s.Path,
+			ShortURL: scheme + "://" + r.Host + opts.link(s.Path),
+			URL:      s.Site,
+			Created:  created,
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		apiRespond(w, status, res)
+	}
+}
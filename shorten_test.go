@@ -0,0 +1,77 @@
+package urlshort
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"urlshort/persist"
+)
+
+// brokenSequence is a store whose sequence cannot be read
+type brokenSequence struct{ persist.Store }
+
+func (brokenSequence) NextSequence() (uint64, error) { return 0, errors.New("disk on fire") }
+
+func TestShortenHandler(t *testing.T) {
+	shorten := func(h http.Handler, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(url.Values{"url": {target}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		h.ServeHTTP(w, r)
+		return w
+	}
+	newHandler := func(store persist.Store, opts Options) (*Table, http.Handler) {
+		table, err := NewTable(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return table, SetHandler(table, opts)
+	}
+
+	_, h := newHandler(persist.NewMemory(), Options{})
+	if w := shorten(h, "https://example.com/a"); w.Code != http.StatusNotFound {
+		t.Errorf("shorten without enabling it = %v, want %v", w.Code, http.StatusNotFound)
+	}
+
+	opts := Options{AdminToken: "s3", Enable: []string{FeatureShorten}}
+	table, h := newHandler(persist.NewMemory(), opts)
+	w := shorten(h, "https://example.com/a")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("shorten = %v %s, want %v", w.Code, w.Body, http.StatusCreated)
+	}
+	var res shortenResult
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if w := shorten(h, "ftp://example.com/a"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("shorten of a bad URL = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	// A vanity link cannot take the generated code, only a generated one can
+	put := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, APILinks+"/"+res.Code, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer s3")
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := put(`{"url": "https://example.com/b"}`); code != http.StatusConflict {
+		t.Errorf("PUT over a generated code = %v, want %v", code, http.StatusConflict)
+	}
+	if s, _ := table.Lookup(res.Code); s.Site != "https://example.com/a" {
+		t.Errorf("generated code now points to %v", s.Site)
+	}
+	if code := put(`{"url": "https://example.com/a", "description": "A", "generated": true}`); code != http.StatusOK {
+		t.Errorf("PUT of the generated link = %v, want %v", code, http.StatusOK)
+	}
+
+	_, h = newHandler(brokenSequence{persist.NewMemory()}, opts)
+	if w := shorten(h, "https://example.com/a"); w.Code != http.StatusInternalServerError {
+		t.Errorf("shorten with a broken store = %v, want %v", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,305 @@
+package urlshort
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"urlshort/persist"
+)
+
+// DBSource is the origin of links that are only defined in the store
+const DBSource = "db"
+
+// Source is a link file, or a directory of link files, merged into the
+// table. When several sources define a path the one with the highest
+// Priority wins. Files in a directory share its priority and later names
+// win over earlier ones. A source named DBSource, without a File, ranks the
+// store itself: a stored link that differs from the files is kept when no
+// file defining it has a higher priority.
+type Source struct {
+	Name     string
+	File     string
+	Priority int
+}
+
+// Definition is what one source says a path points to
+type Definition struct {
+	Source   string
+	Priority int
+	Site     string
+}
+
+// Conflict is a path that sources define differently. Winner names the
+// definition in use.
+type Conflict struct {
+	Path   string
+	Winner string
+	Defs   []Definition
+}
+
+// linkExts lists the extensions of the files read from source directories
+var linkExts = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".toml": true, ".csv": true}
+
+// expand lists the files making up src, ordered from weakest to strongest.
+// Sources that do not exist expand to nothing.
+func (src Source) expand() ([]Source, error) {
+	fi, err := os.Stat(src.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []Source{src}, nil
+	}
+	entries, err := ioutil.ReadDir(src.File)
+	if err != nil {
+		return nil, err
+	}
+	var files []Source
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !linkExts[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		files = append(files, Source{
+			Name:     src.Name + "/" + name,
+			File:     filepath.Join(src.File, name),
+			Priority: src.Priority,
+		})
+	}
+	return files, nil
+}
+
+// merged is the combined view of several sources
+type merged struct {
+	links     []persist.Short
+	origins   map[string]string
+	defs      map[string][]Definition
+	conflicts map[string]*Conflict
+}
+
+// mergeSources reads every source and keeps, for each path, the definition
+// of the strongest source. A source that fails to parse fails the merge so
+// a broken file never removes links.
+func mergeSources(sources []Source) (*merged, error) {
+	var files []Source
+	for _, src := range sources {
+		if src.Name == DBSource {
+			continue
+		}
+		f, err := src.expand()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no link files found: %w", os.ErrNotExist)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Priority < files[j].Priority })
+
+	m := &merged{
+		origins:   make(map[string]string),
+		defs:      make(map[string][]Definition),
+		conflicts: make(map[string]*Conflict),
+	}
+	win := make(map[string]persist.Short)
+	for _, f := range files {
+		links, err := ReadLinkFile(f.File)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range links {
+			win[s.Path] = s
+			m.origins[s.Path] = f.Name
+			m.defs[s.Path] = append(m.defs[s.Path], Definition{Source: f.Name, Priority: f.Priority, Site: s.Site})
+		}
+	}
+	for p, d := range m.defs {
+		for _, def := range d[1:] {
+			if def.Site != d[0].Site {
+				m.conflicts[p] = &Conflict{Path: p, Winner: m.origins[p], Defs: d}
+				break
+			}
+		}
+	}
+	for _, s := range win {
+		m.links = append(m.links, s)
+	}
+	return m, nil
+}
+
+// SyncSources merges the links of every source into the table under policy
+// and returns the changes made, recording where each link came from and
+// which paths the sources disagree on. Under SyncDB a stored link that
+// differs from the files is kept and reported as a conflict too. Nothing
+// is changed on a dry run. Links under reserved paths are skipped.
+func (t *Table) SyncSources(sources []Source, policy string, dryRun bool) ([]Change, error) {
+	p, err := t.PlanSync(sources, policy, t.Reserved())
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return p.Changes, nil
+	}
+	return p.Changes, t.ApplySync(p)
+}
+
+// SyncPlan holds the changes merging link sources makes, checked but not
+// yet written
+type SyncPlan struct {
+	Changes   []Change
+	origins   map[string]string
+	conflicts []Conflict
+}
+
+// PlanSync works out what SyncSources would change, leaving out links under
+// the reserved names, without changing anything. It fails when the table
+// would end up with a link that cannot be served, so a reload can check
+// the links before it commits to anything.
+func (t *Table) PlanSync(sources []Source, policy string, reserved []string) (*SyncPlan, error) {
+	m, err := mergeSources(sources)
+	if err != nil {
+		return nil, err
+	}
+	current, err := loadAll(t.store)
+	if err != nil {
+		return nil, err
+	}
+	links := m.links[:0]
+	for _, s := range m.links {
+		if isReserved(reserved, s.Path) {
+			log.Printf("Skipping %v from %v, the path is reserved for a built-in page", s.Path, m.origins[s.Path])
+			continue
+		}
+		if old, ok := current[s.Path]; ok && old.Generated && !s.Generated {
+			log.Printf("Skipping %v from %v, the path is a generated short code", s.Path, m.origins[s.Path])
+			continue
+		}
+		links = append(links, s)
+	}
+	m.links = links
+	db, ranked := dbPriority(sources)
+	for i, s := range m.links {
+		old, ok := current[s.Path]
+		if !ok || old.Site == s.Site {
+			continue
+		}
+		d := m.defs[s.Path]
+		if policy != SyncDB && !(ranked && db > d[len(d)-1].Priority) {
+			continue
+		}
+		c := m.conflicts[s.Path]
+		if c == nil {
+			c = &Conflict{Path: s.Path, Defs: d[len(d)-1:]}
+		}
+		c.Winner = DBSource
+		c.Defs = append(c.Defs, Definition{Source: DBSource, Priority: db, Site: old.Site})
+		m.conflicts[s.Path] = c
+		m.origins[s.Path] = DBSource
+		// Keep the stored link whatever the policy
+		m.links[i] = old
+	}
+	conflicts := make([]Conflict, 0, len(m.conflicts))
+	for _, c := range m.conflicts {
+		conflicts = append(conflicts, *c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	for _, c := range conflicts {
+		log.Printf("Conflicting definitions of %v, using %v", c.Path, c.Winner)
+	}
+
+	changes, err := Diff(m.links, current, policy)
+	if err != nil {
+		return nil, err
+	}
+	result := current
+	for _, c := range changes {
+		switch c.Op {
+		case OpAdd, OpUpdate:
+			result[c.Path] = c.New
+		case OpDelete:
+			delete(result, c.Path)
+		}
+	}
+	if err := checkLinks(result); err != nil {
+		return nil, err
+	}
+	return &SyncPlan{Changes: changes, origins: m.origins, conflicts: conflicts}, nil
+}
+
+// dbPriority returns the priority sources give the store, if any
+func dbPriority(sources []Source) (int, bool) {
+	for _, src := range sources {
+		if src.Name == DBSource {
+			return src.Priority, true
+		}
+	}
+	return 0, false
+}
+
+// ApplySync writes the changes of p and serves the result
+func (t *Table) ApplySync(p *SyncPlan) error {
+	var save []persist.Short
+	for _, c := range p.Changes {
+		switch c.Op {
+		case OpAdd, OpUpdate:
+			save = append(save, c.New)
+		case OpDelete:
+			if err := t.Delete(c.Path); err != nil && err != persist.ErrNotFound {
+				return err
+			}
+		}
+	}
+	if len(save) > 0 {
+		if err := t.SaveAll(save); err != nil {
+			return err
+		}
+	}
+
+	t.srcMu.Lock()
+	t.origins, t.conflicts = p.origins, p.conflicts
+	t.srcMu.Unlock()
+	return nil
+}
+
+// Origin names the source path was defined by at the last sync, DBSource
+// for links only found in the store
+func (t *Table) Origin(path string) string {
+	t.srcMu.Lock()
+	defer t.srcMu.Unlock()
+	if o, ok := t.origins[path]; ok {
+		return o
+	}
+	return DBSource
+}
+
+// Conflicts returns the paths defined differently by several sources at the
+// last sync
+func (t *Table) Conflicts() []Conflict {
+	t.srcMu.Lock()
+	defer t.srcMu.Unlock()
+	return t.conflicts
+}
+
+// conflictsHandler lists the paths sources disagree on
+func conflictsHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conflictsTemplate, err := parseTemplate("conflicts", opts)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "cannot show the page", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		render(w, r, conflictsTemplate, "conflicts", table.Conflicts())
+	}
+}
@@ -0,0 +1,59 @@
+package urlshort
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"urlshort/persist"
+)
+
+// TestDBPriority checks that a stored link changed away from the files is
+// kept only when the store ranks above the file defining it
+func TestDBPriority(t *testing.T) {
+	dir, err := ioutil.TempDir("", "map-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "team.yaml")
+	if err := ioutil.WriteFile(file, []byte("- path: nyt\n  url: https://www.nytimes.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const changed = "https://www.nytimes.com/section/world"
+
+	for _, tt := range []struct {
+		name    string
+		sources []Source
+		want    string
+	}{
+		{"unranked", []Source{{Name: "team", File: file, Priority: 20}}, "https://www.nytimes.com"},
+		{"below", []Source{{Name: "team", File: file, Priority: 20}, {Name: DBSource, Priority: 10}}, "https://www.nytimes.com"},
+		{"above", []Source{{Name: "team", File: file, Priority: 20}, {Name: DBSource, Priority: 30}}, changed},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewTable(persist.NewMemory())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := table.Save(persist.Short{Path: "nyt", Site: changed}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := table.SyncSources(tt.sources, SyncUnion, false); err != nil {
+				t.Fatal(err)
+			}
+			s, _ := table.Lookup("nyt")
+			if s.Site != tt.want {
+				t.Errorf("nyt -> %v, want %v", s.Site, tt.want)
+			}
+			kept := tt.want == changed
+			if origin := table.Origin("nyt"); (origin == DBSource) != kept {
+				t.Errorf("origin of nyt = %v", origin)
+			}
+			if conflicts := table.Conflicts(); kept && (len(conflicts) != 1 || conflicts[0].Winner != DBSource) {
+				t.Errorf("conflicts = %+v, want one won by %v", conflicts, DBSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package urlshort
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Suggestions offered for a missed path
+const (
+	maxSuggestions = 5
+	maxSuggestLen  = 100 // longer paths are only matched by substring
+	minSubstring   = 3   // shorter paths are only matched by typos
+)
+
+// Suggestion is an existing link close to a path that was not found
+type Suggestion struct {
+	Path        string `json:"path"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	// Distance is the number of characters to change to go from the
+	// missed path to Path, ignoring case
+	Distance int `json:"distance"`
+	// Substring is set when one of the paths contains the other
+	Substring bool `json:"substring,omitempty"`
+}
+
+// Suggest returns up to n links whose paths are close to path: those
+// containing it or contained in it, then those within a few typos of it.
+// Containment only counts when the shorter path has at least minSubstring
+// characters, so that /a does not rank every path with an a first.
+// Rewrite rules are left out.
+func (t *Table) Suggest(path string, n int) []Suggestion {
+	q := strings.ToLower(strings.Trim(path, "/"))
+	if q == "" || n <= 0 {
+		return nil
+	}
+	// Allow one typo per three characters, and at least one
+	limit := len([]rune(q))/3 + 1
+	var list []Suggestion
+	for k, s := range t.snapshot() {
+		if isRule(k) {
+			continue
+		}
+		p := strings.ToLower(k)
+		short := p
+		if len([]rune(q)) < len([]rune(p)) {
+			short = q
+		}
+		sub := len([]rune(short)) >= minSubstring && (strings.Contains(p, q) || strings.Contains(q, p))
+		d := limit + 1
+		if len(p) <= maxSuggestLen && len(q) <= maxSuggestLen {
+			d = editDistance(q, p)
+		}
+		if !sub && d > limit {
+			continue
+		}
+		list = append(list, Suggestion{Path: k, URL: s.Site, Description: s.Description, Distance: d, Substring: sub})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Substring != b.Substring {
+			return a.Substring
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Path < b.Path
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// editDistance is the Levenshtein distance between a and b in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// missPage is what the not found page shows
+type missPage struct {
+	Path        string
+	Suggestions []Suggestion
+	CanCreate   bool
+	Error       string
+	URL         string
+	Description string
+	Tags        string // comma separated
+	Creator     string
+	CSRF        string
+}
+
+// notFoundHandler answers paths no link or page serves with the closest
+// links and, when creating is enabled and the server has an admin token, a
+// form to add the missing one
+func notFoundHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("Path not found: %v\n", r.URL.Path)
+		page := missPage{Path: strings.Trim(r.URL.Path, "/"), CanCreate: opts.enabled(FeatureCreate) && opts.AdminToken != ""}
+		page.CanCreate = page.CanCreate && !table.isReserved(page.Path)
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			table.Miss(page.Path, time.Now())
+		}
+		if page.CanCreate {
+			page.CSRF = csrfToken(w, r, opts)
+		}
+		page.Suggestions = table.Suggest(page.Path, maxSuggestions)
+		renderMiss(w, r, opts, http.StatusNotFound, page)
+	}
+}
+
+func renderMiss(w http.ResponseWriter, r *http.Request, opts Options, status int, page missPage) {
+	notFoundTemplate, err := parseTemplate("notfound", opts)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "cannot show the page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	render(w, r, notFoundTemplate, "notfound", page)
+}
+
+// createHandler saves the link posted from the not found page and sends
+// the visitor on to it. Creating asks for the admin token, as the list page
+// does. The form is shown again with the reason when the link cannot be
+// saved.
+func createHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if status, msg := checkToken(opts.AdminToken, w, r); status != 0 {
+			http.Error(w, msg, status)
+			return
+		}
+		if err := checkCSRF(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		form := linkForm{Tags: r.FormValue("tags")}
+		s := persist.Short{
+			Path:        strings.Trim(r.FormValue("path"), "/"),
+			Site:        strings.TrimSpace(r.FormValue("url")),
+			Description: strings.TrimSpace(r.FormValue("description")),
+			Tags:        form.tags(),
+			Creator:     strings.TrimSpace(r.FormValue("creator")),
+		}
+		page := missPage{
+			Path: s.Path, URL: s.Site, Description: s.Description, Tags: form.Tags, Creator: s.Creator,
+			CanCreate: true, CSRF: csrfToken(w, r, opts),
+		}
+		status := http.StatusBadRequest
+		if _, ok := table.Lookup(s.Path); ok {
+			page.Error, status = "/"+s.Path+" already exists.", http.StatusConflict
+		} else if err := checkLink(s); err != nil {
+			page.Error = err.Error()
+		} else if err := checkAbsolute(s.Site); err != nil {
+			page.Error = err.Error()
+		} else if err := table.checkReserved(s.Path); err != nil {
+			page.Error = err.Error()
+		} else if err := table.Save(s); err != nil {
+			page.Error, status = err.Error(), http.StatusInternalServerError
+		} else {
+			log.Printf("Created %v -> %v", s.Path, s.Site)
+			http.Redirect(w, r, opts.link(s.Path), http.StatusSeeOther)
+			return
+		}
+		page.Suggestions = table.Suggest(s.Path, maxSuggestions)
+		renderMiss(w, r, opts, status, page)
+	}
+}
+
+// suggestHandler returns the links closest to ?q= as JSON, at most ?n=
+func suggestHandler(table *Table) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := maxSuggestions
+		if v := r.URL.Query().Get("n"); v != "" {
+			var err error
+			if n, err = strconv.Atoi(v); err != nil || n < 1 || n > 100 {
+				http.Error(w, "n must be a number from 1 to 100", http.StatusBadRequest)
+				return
+			}
+		}
+		list := table.Suggest(r.URL.Query().Get("q"), n)
+		if list == nil {
+			list = []Suggestion{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}
+}
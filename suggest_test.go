@@ -0,0 +1,96 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"urlshort/persist"
+)
+
+func TestSuggest(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links []persist.Short
+	for _, p := range []string{"github", "gh", "go", "golang", "news/daily"} {
+		links = append(links, persist.Short{Path: p, Site: "https://" + p + ".example"})
+	}
+	if err := table.SaveAll(links); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		q    string
+		want []string
+	}{
+		// Too short to match by substring, only typos count
+		{"g", []string{"gh", "go"}},
+		{"go", []string{"go", "gh"}},
+		{"git", []string{"github", "gh", "go"}},
+		// go is in golan, but too short to rank by it
+		{"golan", []string{"golang"}},
+		{"GitHub", []string{"github"}},
+		{"github/issues", []string{"github"}},
+		{"daily", []string{"news/daily"}},
+		{"", nil},
+	} {
+		var got []string
+		for _, s := range table.Suggest(tt.q, maxSuggestions) {
+			got = append(got, s.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+// TestCreateHandler checks that only the admin can create a missing link
+// and that it keeps the tags and creator given
+func TestCreateHandler(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := SetHandler(table, Options{AdminToken: "s3"})
+	create := func(auth string) int {
+		form := url.Values{
+			"path": {"wiki"}, "url": {"https://wiki.example.com"}, "tags": {"docs, team"},
+			"creator": {"ana"}, "csrf": {"t0k"},
+		}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: csrfCookie, Value: "t0k"})
+		if auth != "" {
+			r.SetBasicAuth("", auth)
+		}
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := create(""); code != http.StatusUnauthorized {
+		t.Errorf("create without the token = %v, want %v", code, http.StatusUnauthorized)
+	}
+	if code := create("guess"); code != http.StatusUnauthorized {
+		t.Errorf("create with a wrong token = %v, want %v", code, http.StatusUnauthorized)
+	}
+	if _, ok := table.Lookup("wiki"); ok {
+		t.Fatal("an anonymous visitor created /wiki")
+	}
+	if code := create("s3"); code != http.StatusSeeOther {
+		t.Fatalf("create = %v, want %v", code, http.StatusSeeOther)
+	}
+	s, _ := table.Lookup("wiki")
+	if !reflect.DeepEqual(s.Tags, []string{"docs", "team"}) || s.Creator != "ana" {
+		t.Errorf("created %+v, want the tags and creator given", s)
+	}
+
+	w := httptest.NewRecorder()
+	SetHandler(table, Options{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if strings.Contains(w.Body.String(), "<form") {
+		t.Error("the not found page offers to create links on a server without an admin token")
+	}
+}
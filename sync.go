@@ -0,0 +1,126 @@
+package urlshort
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Policies deciding how a link file is merged into the store
+const (
+	// SyncFile makes the store match the file, deleting links it lacks
+	SyncFile = "file"
+	// SyncDB only adds paths the store does not have yet
+	SyncDB = "db"
+	// SyncUnion adds new paths and lets the file win on conflicts, but
+	// never deletes
+	SyncUnion = "union"
+)
+
+// SyncPolicies lists the policies accepted by Sync and Diff
+var SyncPolicies = []string{SyncFile, SyncDB, SyncUnion}
+
+// Change operations
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Change is one modification a sync makes to the store
+type Change struct {
+	Op   string
+	Path string
+	Old  persist.Short // the stored link, zero for OpAdd
+	New  persist.Short // the link after the change, zero for OpDelete
+}
+
+func (c Change) String() string {
+	switch c.Op {
+	case OpAdd:
+		return fmt.Sprintf("+ %v %v", c.Path, c.New.Site)
+	case OpDelete:
+		return fmt.Sprintf("- %v %v", c.Path, c.Old.Site)
+	}
+	if c.Old.Site != c.New.Site {
+		return fmt.Sprintf("~ %v %v -> %v", c.Path, c.Old.Site, c.New.Site)
+	}
+	return fmt.Sprintf("~ %v details", c.Path)
+}
+
+// ReadLinkFile reads the links defined in file, in any format Decode
+// understands, guessing the format from the file name
+func ReadLinkFile(file string) ([]persist.Short, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	links, err := Decode(f, FormatOf(file))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", file, err)
+	}
+	return links, nil
+}
+
+// Diff lists the changes that merging links into current under policy
+// would make, sorted by path. Updates start from the stored link so visit
+// counts, creation time and metadata the file does not carry are kept.
+func Diff(links []persist.Short, current map[string]persist.Short, policy string) ([]Change, error) {
+	switch policy {
+	case SyncFile, SyncDB, SyncUnion:
+	default:
+		return nil, fmt.Errorf("unknown sync policy %q", policy)
+	}
+	var changes []Change
+	defined := make(map[string]bool, len(links))
+	for _, s := range links {
+		defined[s.Path] = true
+		old, ok := current[s.Path]
+		if !ok {
+			s.Count, s.LastVisited = 0, time.Time{}
+			changes = append(changes, Change{Op: OpAdd, Path: s.Path, New: s})
+			continue
+		}
+		if policy == SyncDB {
+			continue
+		}
+		n := old
+		n.Site = s.Site
+		if s.Description != "" {
+			n.Description = s.Description
+		}
+		if len(s.Tags) > 0 {
+			n.Tags = s.Tags
+		}
+		if s.Creator != "" {
+			n.Creator = s.Creator
+		}
+		if s.Forward != (persist.Forward{}) {
+			n.Forward = s.Forward
+		}
+		if n.Site != old.Site || n.Description != old.Description || !reflect.DeepEqual(n.Tags, old.Tags) ||
+			n.Creator != old.Creator || n.Forward != old.Forward {
+			changes = append(changes, Change{Op: OpUpdate, Path: s.Path, Old: old, New: n})
+		}
+	}
+	if policy == SyncFile {
+		for k, v := range current {
+			if !defined[k] {
+				changes = append(changes, Change{Op: OpDelete, Path: k, Old: v})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Sync merges the links defined in file into the table under policy and
+// returns the changes made. Nothing is changed on a dry run.
+func (t *Table) Sync(file, policy string, dryRun bool) ([]Change, error) {
+	return t.SyncSources([]Source{{Name: file, File: file}}, policy, dryRun)
+}
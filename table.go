@@ -0,0 +1,442 @@
+package urlshort
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"urlshort/persist"
+)
+
+// Table answers redirect lookups from an immutable in-memory snapshot of the
+// link table. The snapshot is replaced wholesale whenever links change so
+// lookups never lock or touch the store. Visits are counted and aggregated
+// for analytics in memory and flushed to the store in the background.
+type Table struct {
+	store persist.Store
+	links atomic.Value // map[string]persist.Short, never modified once stored
+	rule  atomic.Value // []rule compiled from links
+
+	reserved atomic.Value // []string taken by built-in pages, see Reserve
+
+	// mu serialises writers building a new snapshot
+	mu     sync.Mutex
+	visits sync.Map // path -> *visits not yet flushed
+	hits   sync.Map // hitKey -> *int64 analytics not yet flushed
+
+	missMu       sync.Mutex
+	misses       map[string]*persist.Miss // misses not yet flushed
+	missRecorded int                      // paths flushed since the store was last pruned
+
+	srcMu     sync.Mutex
+	origins   map[string]string // path -> source that defined it at the last sync
+	conflicts []Conflict
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTable loads every link in store into a new Table
+func NewTable(store persist.Store) (*Table, error) {
+	t := &Table{store: store}
+	m, err := loadAll(store)
+	if err != nil {
+		return nil, err
+	}
+	t.install(m)
+	return t, nil
+}
+
+// Store returns the store backing the table
+func (t *Table) Store() persist.Store {
+	return t.store
+}
+
+// Reload replaces the snapshot with the current contents of the store. The
+// current snapshot is kept if the store cannot be read or holds links that
+// cannot be served.
+func (t *Table) Reload() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m, err := loadAll(t.store)
+	if err != nil {
+		return err
+	}
+	if err := checkLinks(m); err != nil {
+		return err
+	}
+	t.install(m)
+	return nil
+}
+
+// install serves the links in m, compiling the rewrite rules among them
+func (t *Table) install(m map[string]persist.Short) {
+	t.rule.Store(compileRules(m))
+	t.links.Store(m)
+}
+
+func (t *Table) rules() []rule {
+	r, _ := t.rule.Load().([]rule)
+	return r
+}
+
+// checkLinks reports links without a path, a usable URL or valid forwarding
+// rules
+func checkLinks(m map[string]persist.Short) error {
+	var bad []string
+	for k, s := range m {
+		if k == "" || checkLink(s) != nil {
+			bad = append(bad, fmt.Sprintf("%q", k))
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	sort.Strings(bad)
+	return fmt.Errorf("links without a usable URL: %v", strings.Join(bad, ", "))
+}
+
+// checkLink reports why s cannot be served
+func checkLink(s persist.Short) error {
+	if s.Path == "" {
+		return errors.New("the shortcut is empty")
+	}
+	if s.Site == "" {
+		return errors.New("the URL is empty")
+	}
+	if u, err := url.Parse(paramRe.ReplaceAllString(s.Site, "x")); err != nil || u.Scheme == "" && u.Host == "" && u.Path == "" {
+		return fmt.Errorf("%q is not a usable URL", s.Site)
+	}
+	if err := s.Forward.Check(); err != nil {
+		return err
+	}
+	return checkRule(s)
+}
+
+// Len returns the number of links being served
+func (t *Table) Len() int {
+	return len(t.snapshot())
+}
+
+func (t *Table) snapshot() map[string]persist.Short {
+	m, _ := t.links.Load().(map[string]persist.Short)
+	return m
+}
+
+// Restore replaces the store with the backup read from r and reloads the
+// snapshot. Buffered visits are flushed first so they are not applied to
+// the restored links. A backup holding links that cannot be served leaves
+// the store as it was.
+func (t *Table) Restore(r io.Reader) error {
+	t.Flush()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var old bytes.Buffer
+	if err := t.store.Backup(&old); err != nil {
+		return err
+	}
+	if err := t.store.Restore(r); err != nil {
+		return err
+	}
+	m, err := loadAll(t.store)
+	if err == nil {
+		err = checkLinks(m)
+	}
+	if err != nil {
+		if rerr := t.store.Restore(&old); rerr != nil {
+			return fmt.Errorf("%v; putting the previous links back failed: %v", err, rerr)
+		}
+		return err
+	}
+	t.install(m)
+	return nil
+}
+
+// Lookup returns the link stored under path
+func (t *Table) Lookup(path string) (persist.Short, bool) {
+	s, ok := t.snapshot()[path]
+	return s, ok
+}
+
+// visits buffers the visits to one path between flushes
+type visits struct {
+	count int64
+	last  int64 // Unix nanoseconds of the latest visit
+}
+
+// hitKey groups visits that land in the same analytics bucket
+type hitKey struct {
+	path     string
+	hour     int64
+	referrer string
+	agent    string
+}
+
+// Visit counts visit v. The count and analytics reach the store on the
+// next Flush.
+func (t *Table) Visit(v persist.Visit) {
+	p, ok := t.visits.Load(v.Path)
+	if !ok {
+		p, _ = t.visits.LoadOrStore(v.Path, new(visits))
+	}
+	atomic.AddInt64(&p.(*visits).count, 1)
+	atomic.StoreInt64(&p.(*visits).last, v.Time.UnixNano())
+
+	k := hitKey{v.Path, v.Time.Unix() / 3600, v.Referrer, v.Agent}
+	h, ok := t.hits.Load(k)
+	if !ok {
+		h, _ = t.hits.LoadOrStore(k, new(int64))
+	}
+	atomic.AddInt64(h.(*int64), 1)
+}
+
+// ErrGenerated is returned when a link that is not Generated would replace
+// a generated short code, which may already be handed out
+var ErrGenerated = errors.New("taken by a generated short code")
+
+// Save stores s and swaps in a snapshot that includes it. Created and
+// Updated are maintained here: Created is kept from the stored link when s
+// replaces one and Updated is set to now. Visit counts of the replaced link
+// are kept unless s brings its own. Only a Generated link may replace a
+// generated one, ErrGenerated is returned otherwise.
+func (t *Table) Save(s persist.Short) error {
+	return t.SaveAll([]persist.Short{s})
+}
+
+// SaveAll stores links with a single snapshot swap, keeping Created and
+// setting Updated as Save does
+func (t *Table) SaveAll(links []persist.Short) error {
+	for _, s := range links {
+		if err := checkRule(s); err != nil {
+			return err
+		}
+		if err := t.checkReserved(s.Path); err != nil {
+			return err
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.saveAll(links)
+}
+
+// SaveIf saves s as Save does once check has approved the link it replaces,
+// if any, with no other writer in between
+func (t *Table) SaveIf(s persist.Short, check func(old persist.Short, exists bool) error) error {
+	if err := checkRule(s); err != nil {
+		return err
+	}
+	if err := t.checkReserved(s.Path); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, ok := t.snapshot()[s.Path]
+	if err := check(old, ok); err != nil {
+		return err
+	}
+	return t.saveAll([]persist.Short{s})
+}
+
+// saveAll is SaveAll for callers holding mu
+func (t *Table) saveAll(links []persist.Short) error {
+	now := time.Now()
+	old := t.snapshot()
+	m := make(map[string]persist.Short, len(links))
+	snap := make(map[string]persist.Short, len(links))
+	for _, s := range links {
+		var o persist.Short
+		if cur, ok := old[s.Path]; ok {
+			if cur.Generated && !s.Generated {
+				return fmt.Errorf("/%v is %w", s.Path, ErrGenerated)
+			}
+			// The snapshot does not follow flushed counts, the store does
+			if stored, err := t.store.Get(s.Path); err == nil {
+				o = *stored
+			}
+			if !o.Created.IsZero() {
+				s.Created = o.Created
+			}
+		}
+		if s.Created.IsZero() {
+			s.Created = now
+		}
+		s.Updated = now
+		// SaveMap keeps the stored count of links without one, counting
+		// visits flushed since o was read
+		m[s.Path] = s
+		if s.Count == 0 {
+			s.Count, s.LastVisited = o.Count, o.LastVisited
+		}
+		snap[s.Path] = s
+	}
+	if err := t.store.SaveMap(m); err != nil {
+		return err
+	}
+	t.swap(func(sm map[string]persist.Short) {
+		for k, v := range snap {
+			sm[k] = v
+		}
+	})
+	return nil
+}
+
+// Delete removes path from the store and the snapshot
+func (t *Table) Delete(path string) error {
+	return t.DeleteIf(path, func(persist.Short) error { return nil })
+}
+
+// DeleteIf deletes path once check has approved the link stored there, with
+// no other writer in between. It returns persist.ErrNotFound when path is
+// missing.
+func (t *Table) DeleteIf(path string, check func(old persist.Short) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, ok := t.snapshot()[path]
+	if !ok {
+		return persist.ErrNotFound
+	}
+	if err := check(old); err != nil {
+		return err
+	}
+	if err := t.store.Delete(path); err != nil {
+		return err
+	}
+	t.swap(func(m map[string]persist.Short) { delete(m, path) })
+	return nil
+}
+
+// swap copies the snapshot, applies change to the copy and publishes it.
+// Callers must hold mu.
+func (t *Table) swap(change func(map[string]persist.Short)) {
+	old := t.snapshot()
+	m := make(map[string]persist.Short, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	change(m)
+	t.install(m)
+}
+
+// All returns every stored link with visits that are still buffered added to
+// its count
+func (t *Table) All() []persist.Short {
+	var ss []persist.Short
+	for _, v := range getall(t.store) {
+		if p, ok := t.visits.Load(v.Path); ok {
+			n := atomic.LoadInt64(&p.(*visits).count)
+			if n > 0 {
+				v.Count += int(n)
+				v.LastVisited = time.Unix(0, atomic.LoadInt64(&p.(*visits).last))
+			}
+		}
+		ss = append(ss, v)
+	}
+	return ss
+}
+
+// Flush writes buffered visit counts, analytics and misses to the store
+func (t *Table) Flush() error {
+	ferr := t.flushMisses()
+	// Buckets of past hours get no more visits once flushed, so they are
+	// dropped when empty. The current hour and the one before are kept as
+	// a visit may still be on its way to them.
+	stale := time.Now().Unix()/3600 - 1
+	var vs []persist.Visit
+	var counters []*int64
+	t.hits.Range(func(key, h interface{}) bool {
+		k := key.(hitKey)
+		n := atomic.SwapInt64(h.(*int64), 0)
+		if n == 0 {
+			if k.hour < stale {
+				t.hits.Delete(k)
+			}
+			return true
+		}
+		vs = append(vs, persist.Visit{
+			Path:     k.path,
+			Time:     time.Unix(k.hour*3600, 0),
+			Referrer: k.referrer,
+			Agent:    k.agent,
+			Count:    int(n),
+		})
+		counters = append(counters, h.(*int64))
+		return true
+	})
+	if len(vs) > 0 {
+		if err := t.store.RecordVisits(vs); err != nil {
+			log.Printf("Failed to record visit analytics: %v", err)
+			ferr = err
+			// Keep them for the next flush
+			for i, h := range counters {
+				atomic.AddInt64(h, int64(vs[i].Count))
+			}
+		}
+	}
+	t.visits.Range(func(k, v interface{}) bool {
+		n := atomic.SwapInt64(&v.(*visits).count, 0)
+		if n == 0 {
+			return true
+		}
+		last := time.Unix(0, atomic.LoadInt64(&v.(*visits).last))
+		if err := t.store.Incr(k.(string), int(n), last); err != nil {
+			log.Printf("Failed to flush %v visits to %v: %v", n, k, err)
+			ferr = err
+			// Links deleted since the visit have nowhere to keep the count,
+			// other counts are kept for the next flush
+			if !errors.Is(err, persist.ErrNotFound) {
+				atomic.AddInt64(&v.(*visits).count, n)
+			}
+		}
+		return true
+	})
+	return ferr
+}
+
+// Run flushes visit counts every interval and rolls up old analytics every
+// hour until Close is called
+func (t *Table) Run(interval time.Duration) {
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go func() {
+		defer close(t.done)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		rollup := time.NewTicker(time.Hour)
+		defer rollup.Stop()
+		t.rollup()
+		for {
+			select {
+			case <-tick.C:
+				t.Flush()
+			case <-rollup.C:
+				t.rollup()
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (t *Table) rollup() {
+	if err := t.store.Rollup(time.Now().Add(-hourlyRetention)); err != nil {
+		log.Printf("Failed to roll up visit analytics: %v", err)
+	}
+}
+
+// Close stops background flushing and writes out any remaining counts
+func (t *Table) Close() error {
+	if t.stop != nil {
+		close(t.stop)
+		<-t.done
+		t.stop = nil
+	}
+	return t.Flush()
+}
@@ -0,0 +1,263 @@
+package urlshort
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"urlshort/persist"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// openStores opens one store of every backend in a temporary directory
+func openStores(t *testing.T) map[string]persist.Store {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "map-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	stores := make(map[string]persist.Store)
+	for _, name := range persist.Backends {
+		s, err := persist.Open(name, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("open %v: %v", name, err)
+		}
+		t.Cleanup(func() { s.Close() })
+		stores[name] = s
+	}
+	return stores
+}
+
+// TestConcurrentVisits sends N visits through Table.Visit from N goroutines
+// while other goroutines flush, and checks the store counts exactly N, in
+// the link and in its analytics
+func TestConcurrentVisits(t *testing.T) {
+	const n = 500
+	for name, store := range openStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save(persist.Short{Path: "nyt", Site: "https://www.nytimes.com"}); err != nil {
+				t.Fatal(err)
+			}
+			table, err := NewTable(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var visitors, flushers sync.WaitGroup
+			stop := make(chan struct{})
+			for i := 0; i < 4; i++ {
+				flushers.Add(1)
+				go func() {
+					defer flushers.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+							table.Flush()
+						}
+					}
+				}()
+			}
+			for i := 0; i < n; i++ {
+				visitors.Add(1)
+				go func() {
+					defer visitors.Done()
+					table.Visit(persist.Visit{Path: "nyt", Time: time.Now(), Count: 1})
+				}()
+			}
+			visitors.Wait()
+			close(stop)
+			flushers.Wait()
+			if err := table.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := store.Get("nyt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Count != n {
+				t.Errorf("count = %v, want %v", s.Count, n)
+			}
+			stats, err := store.Stats("nyt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var hits int
+			for _, b := range stats.Hourly {
+				hits += b.Count
+			}
+			if hits != n {
+				t.Errorf("analytics count %v visits, want %v", hits, n)
+			}
+		})
+	}
+}
+
+// TestSaveKeepsVisits saves a link over and over while its visits are
+// flushed, and checks none of them is lost
+func TestSaveKeepsVisits(t *testing.T) {
+	const n = 200
+	for name, store := range openStores(t) {
+		t.Run(name, func(t *testing.T) {
+			table, err := NewTable(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nyt := persist.Short{Path: "nyt", Site: "https://www.nytimes.com"}
+			if err := table.SaveAll([]persist.Short{nyt}); err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			stop := make(chan struct{})
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					nyt.Description = fmt.Sprint("edit ", i)
+					if err := table.SaveAll([]persist.Short{nyt}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+			for i := 0; i < n; i++ {
+				table.Visit(persist.Visit{Path: "nyt", Time: time.Now(), Count: 1})
+				if err := table.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			close(stop)
+			wg.Wait()
+
+			s, err := store.Get("nyt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Count != n {
+				t.Errorf("count = %v, want %v", s.Count, n)
+			}
+		})
+	}
+}
+
+// flakyStore fails to write visits until it is fixed
+type flakyStore struct {
+	persist.Store
+	broken int32
+}
+
+func (s *flakyStore) Incr(k string, n int, last time.Time) error {
+	if atomic.LoadInt32(&s.broken) != 0 {
+		return errors.New("disk full")
+	}
+	return s.Store.Incr(k, n, last)
+}
+
+func (s *flakyStore) RecordVisits(vs []persist.Visit) error {
+	if atomic.LoadInt32(&s.broken) != 0 {
+		return errors.New("disk full")
+	}
+	return s.Store.RecordVisits(vs)
+}
+
+// TestFlushRetries checks that visits a failed flush could not write are
+// written by the next one, and that those of deleted links are dropped
+func TestFlushRetries(t *testing.T) {
+	store := &flakyStore{Store: persist.NewMemory(), broken: 1}
+	if err := store.Save(persist.Short{Path: "nyt", Site: "https://www.nytimes.com"}); err != nil {
+		t.Fatal(err)
+	}
+	table, err := NewTable(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		table.Visit(persist.Visit{Path: "nyt", Time: time.Now(), Count: 1})
+	}
+	table.Visit(persist.Visit{Path: "gone", Time: time.Now(), Count: 1})
+	if err := table.Flush(); err == nil {
+		t.Fatal("flush to a broken store succeeded")
+	}
+	atomic.StoreInt32(&store.broken, 0)
+	if err := table.Flush(); err == nil {
+		t.Error("flush of visits to a missing link succeeded")
+	}
+	if err := table.Flush(); err != nil {
+		t.Errorf("visits to a missing link were kept: %v", err)
+	}
+
+	s, err := store.Get("nyt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Count != 3 {
+		t.Errorf("count = %v, want 3", s.Count)
+	}
+	stats, err := store.Stats("nyt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Hourly) != 1 || stats.Hourly[0].Count != 3 {
+		t.Errorf("analytics = %+v, want 3 visits", stats.Hourly)
+	}
+}
+
+// TestRestoreKeepsStore checks that a backup holding a link that cannot be
+// served is refused without touching the store
+func TestRestoreKeepsStore(t *testing.T) {
+	for name, store := range openStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save(persist.Short{Path: "bad"}); err != nil {
+				t.Fatal(err)
+			}
+			var backup bytes.Buffer
+			if err := store.Backup(&backup); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Delete("bad"); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save(persist.Short{Path: "nyt", Site: "https://www.nytimes.com"}); err != nil {
+				t.Fatal(err)
+			}
+			table, err := NewTable(store)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := table.Restore(&backup); err == nil {
+				t.Fatal("restored a link without a URL")
+			}
+			if _, err := store.Get("bad"); err == nil {
+				t.Error("the store kept the link without a URL")
+			}
+			if _, err := store.Get("nyt"); err != nil {
+				t.Errorf("the store lost nyt: %v", err)
+			}
+			if _, ok := table.Lookup("nyt"); !ok {
+				t.Error("nyt is no longer served")
+			}
+		})
+	}
+}
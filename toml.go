@@ -0,0 +1,437 @@
+package urlshort
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// tomlParser reads the subset of TOML used by link files: top level
+// key/value pairs for the flat form, and [[links]] tables holding records.
+// Strings, arrays of strings, integers, booleans and dates are supported.
+type tomlParser struct {
+	b    []byte
+	pos  int
+	line int
+}
+
+func decodeTOML(b []byte) ([]record, error) {
+	p := &tomlParser{b: b, line: 1}
+	flat := make(map[string]string)
+	var recs []record
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			break
+		}
+		if p.peek() == '[' {
+			if !p.consume("[[") {
+				return nil, p.errorf("only [[links]] tables are supported")
+			}
+			p.skipSpace(false)
+			name, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpace(false)
+			if !p.consume("]]") || name != "links" {
+				return nil, p.errorf("only [[links]] tables are supported")
+			}
+			if err := p.endLine(); err != nil {
+				return nil, err
+			}
+			recs = append(recs, record{})
+			continue
+		}
+
+		line := p.line
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if !p.consume("=") {
+			return nil, p.errorf("expected = after %q", k)
+		}
+		p.skipSpace(false)
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endLine(); err != nil {
+			return nil, err
+		}
+		if len(recs) > 0 {
+			err = setField(&recs[len(recs)-1], k, v)
+		} else if s, ok := v.(string); !ok {
+			err = fmt.Errorf("the URL of %q must be a string", k)
+		} else if _, ok := flat[k]; ok {
+			err = fmt.Errorf("%q is defined twice", k)
+		} else {
+			flat[k] = s
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return append(flatRecords(flat), recs...), nil
+}
+
+// setField sets the record field named k, ignoring unknown fields as the
+// JSON and YAML decoders do
+func setField(r *record, k string, v interface{}) error {
+	var ok bool
+	switch k {
+	case "path":
+		r.Path, ok = v.(string)
+	case "url":
+		r.URL, ok = v.(string)
+	case "description":
+		r.Description, ok = v.(string)
+	case "creator":
+		r.Creator, ok = v.(string)
+	case "tags":
+		r.Tags, ok = v.([]string)
+	case "visits":
+		var n int64
+		n, ok = v.(int64)
+		r.Visits = int(n)
+	case "created":
+		r.Created, ok = v.(time.Time)
+	case "updated":
+		r.Updated, ok = v.(time.Time)
+	case "last_visited":
+		r.LastVisited, ok = v.(time.Time)
+	case "path_mode":
+		r.PathMode, ok = v.(string)
+	case "query_mode":
+		r.QueryMode, ok = v.(string)
+	case "fragment":
+		r.Fragment, ok = v.(string)
+	case "params":
+		r.Params, ok = v.(string)
+	case "generated":
+		r.Generated, ok = v.(bool)
+	default:
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("unexpected value for %v", k)
+	}
+	return nil
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool { return p.pos >= len(p.b) }
+
+func (p *tomlParser) peek() byte { return p.b[p.pos] }
+
+func (p *tomlParser) consume(s string) bool {
+	if !strings.HasPrefix(string(p.b[p.pos:]), s) {
+		return false
+	}
+	p.pos += len(s)
+	return true
+}
+
+// skipSpace skips blanks, and also newlines and comments when lines is set
+func (p *tomlParser) skipSpace(lines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+		case lines && c == '\n':
+			p.line++
+		case lines && c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+			continue
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// endLine checks that nothing but a comment follows a value
+func (p *tomlParser) endLine() error {
+	p.skipSpace(false)
+	if !p.eof() && p.peek() == '#' {
+		for !p.eof() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q", p.rest())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+// rest returns what is left of the current line, for error messages
+func (p *tomlParser) rest() string {
+	end := p.pos
+	for end < len(p.b) && p.b[end] != '\n' {
+		end++
+	}
+	return strings.TrimSpace(string(p.b[p.pos:end]))
+}
+
+func (p *tomlParser) key() (string, error) {
+	if p.eof() {
+		return "", p.errorf("expected a key")
+	}
+	var k string
+	if c := p.peek(); c == '"' || c == '\'' {
+		s, err := p.str()
+		if err != nil {
+			return "", err
+		}
+		k = s
+	} else {
+		start := p.pos
+		for !p.eof() && isBareKey(p.peek()) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", p.errorf("expected a key, found %q", p.rest())
+		}
+		k = string(p.b[start:p.pos])
+	}
+	if !p.eof() && p.peek() == '.' {
+		return "", p.errorf("dotted keys are not supported, quote %q", k+p.rest())
+	}
+	return k, nil
+}
+
+func isBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	switch p.peek() {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		return p.array()
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n#,]", rune(p.peek())) {
+		p.pos++
+	}
+	// A space may separate the date from the time
+	if p.pos-start == len("2006-01-02") && p.pos+1 < len(p.b) && p.peek() == ' ' && isDigit(p.b[p.pos+1]) {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n#,]", rune(p.peek())) {
+			p.pos++
+		}
+	}
+	tok := string(p.b[start:p.pos])
+	if tok == "true" || tok == "false" {
+		return tok == "true", nil
+	}
+	if n, err := strconv.ParseInt(strings.Replace(tok, "_", "", -1), 10, 64); err == nil {
+		return n, nil
+	}
+	if t, ok := parseTOMLTime(tok); ok {
+		return t, nil
+	}
+	return nil, p.errorf("unsupported value %q", tok)
+}
+
+// tomlTimes are the layouts of offset date-times, local date-times and
+// local dates. Local ones are read as UTC.
+var tomlTimes = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+func parseTOMLTime(tok string) (time.Time, bool) {
+	tok = strings.ToUpper(strings.Replace(tok, " ", "T", 1))
+	for _, layout := range tomlTimes {
+		if t, err := time.Parse(layout, tok); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// array reads an array of strings, which may span several lines
+func (p *tomlParser) array() ([]string, error) {
+	p.pos++
+	list := []string{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		if c := p.peek(); c != '"' && c != '\'' {
+			return nil, p.errorf("arrays may only hold strings")
+		}
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// str reads a basic "string" with escapes or a literal 'string'
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && quote == '"':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+}
+
+func (p *tomlParser) escape(sb *strings.Builder) error {
+	if p.eof() {
+		return p.errorf("unterminated string")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"', '\\':
+		sb.WriteByte(c)
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.b) {
+			return p.errorf("short unicode escape")
+		}
+		r, err := strconv.ParseUint(string(p.b[p.pos:p.pos+n]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("invalid unicode escape")
+		}
+		p.pos += n
+		sb.WriteRune(rune(r))
+	default:
+		return p.errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// exportTOML writes recs as [[links]] tables
+func exportTOML(w io.Writer, recs []record) error {
+	var sb strings.Builder
+	for i, r := range recs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("[[links]]\n")
+		fmt.Fprintf(&sb, "path = %v\n", tomlString(r.Path))
+		fmt.Fprintf(&sb, "url = %v\n", tomlString(r.URL))
+		if r.Description != "" {
+			fmt.Fprintf(&sb, "description = %v\n", tomlString(r.Description))
+		}
+		if len(r.Tags) > 0 {
+			tags := make([]string, len(r.Tags))
+			for i, t := range r.Tags {
+				tags[i] = tomlString(t)
+			}
+			fmt.Fprintf(&sb, "tags = [%v]\n", strings.Join(tags, ", "))
+		}
+		if r.Creator != "" {
+			fmt.Fprintf(&sb, "creator = %v\n", tomlString(r.Creator))
+		}
+		for _, f := range []struct{ name, v string }{
+			{"path_mode", r.PathMode}, {"query_mode", r.QueryMode}, {"fragment", r.Fragment}, {"params", r.Params},
+		} {
+			if f.v != "" {
+				fmt.Fprintf(&sb, "%v = %v\n", f.name, tomlString(f.v))
+			}
+		}
+		if r.Generated {
+			sb.WriteString("generated = true\n")
+		}
+		if r.Visits != 0 {
+			fmt.Fprintf(&sb, "visits = %v\n", r.Visits)
+		}
+		for _, f := range []struct {
+			name string
+			t    time.Time
+		}{{"created", r.Created}, {"updated", r.Updated}, {"last_visited", r.LastVisited}} {
+			if !f.t.IsZero() {
+				fmt.Fprintf(&sb, "%v = %v\n", f.name, formatTime(f.t))
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
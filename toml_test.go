@@ -0,0 +1,222 @@
+package urlshort
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"urlshort/persist"
+)
+
+// utc moves the times of recs to UTC so they compare with reflect.DeepEqual
+func utc(recs []record) []record {
+	for i := range recs {
+		r := &recs[i]
+		r.Created, r.Updated, r.LastVisited = r.Created.UTC(), r.Updated.UTC(), r.LastVisited.UTC()
+	}
+	return recs
+}
+
+func TestDecodeTOML(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		want []record
+	}{
+		{
+			name: "flat",
+			in:   "nyt = \"https://www.nytimes.com\"\n'go' = 'https://golang.org'\n",
+			want: []record{{Path: "go", URL: "https://golang.org"}, {Path: "nyt", URL: "https://www.nytimes.com"}},
+		},
+		{
+			name: "comments",
+			in: `# links of the team
+[[links]] # first
+path = "nyt" # the paper
+url = "https://www.nytimes.com/#top" # a # inside a string is kept
+# between fields
+tags = [
+  "news", # daily
+  # nothing here
+  "paper",
+]
+`,
+			want: []record{{Path: "nyt", URL: "https://www.nytimes.com/#top", Tags: []string{"news", "paper"}}},
+		},
+		{
+			name: "escapes",
+			in: `[[links]]
+path = "q"
+url = "https://example.com/?q=\"a b\"&c=d\\e"
+description = "tab\there\nline \u00e9\U0001F600 \b\f\r"
+creator = 'C:\Users\no\escapes'
+`,
+			want: []record{{
+				Path:        "q",
+				URL:         `https://example.com/?q="a b"&c=d\e`,
+				Description: "tab\there\nline é😀 \b\f\r",
+				Creator:     `C:\Users\no\escapes`,
+			}},
+		},
+		{
+			name: "arrays",
+			in: `[[links]]
+path = "a"
+url = "https://a.example"
+tags = []
+
+[[links]]
+path = "b"
+url = "https://b.example"
+tags = [ "one",'two' ,
+         "three, four" ]
+`,
+			want: []record{
+				{Path: "a", URL: "https://a.example", Tags: []string{}},
+				{Path: "b", URL: "https://b.example", Tags: []string{"one", "two", "three, four"}},
+			},
+		},
+		{
+			name: "dates and numbers",
+			in: `[[links]]
+path = "nyt"
+url = "https://www.nytimes.com"
+visits = 1_024
+generated = true
+created = 1979-05-27T07:32:00Z
+updated = 1979-05-27T00:32:00-07:00
+last_visited = 1979-05-27 07:32:00.5z
+`,
+			want: []record{{
+				Path:        "nyt",
+				URL:         "https://www.nytimes.com",
+				Visits:      1024,
+				Generated:   true,
+				Created:     time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+				Updated:     time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+				LastVisited: time.Date(1979, 5, 27, 7, 32, 0, 5e8, time.UTC),
+			}},
+		},
+		{
+			name: "local dates",
+			in: `[[links]]
+path = "nyt"
+url = "https://www.nytimes.com"
+created = 1979-05-27
+updated = 1979-05-27T07:32:00
+`,
+			want: []record{{
+				Path:    "nyt",
+				URL:     "https://www.nytimes.com",
+				Created: time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC),
+				Updated: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+			}},
+		},
+		{
+			name: "unknown fields",
+			in:   "[[links]]\npath = \"a\"\nurl = \"https://a.example\"\nowner = \"team\"\n",
+			want: []record{{Path: "a", URL: "https://a.example"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTOML([]byte(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := utc(got), utc(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("decodeTOML =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	for _, tt := range []struct {
+		in, err string
+	}{
+		{"nyt = \"https://www.nytimes.com\n", "line 1: unterminated string"},
+		{"[[links]]\npath = \"a\"\ntags = [\"x\"\n", "line 4: unterminated array"},
+		{"[[links]]\ntags = [\"x\" \"y\"]\n", "line 2: expected , or ] in array"},
+		{"[[links]]\ntags = [1, 2]\n", "line 2: arrays may only hold strings"},
+		{"[[links]]\npath = \"\\q\"\n", "line 2: invalid escape \\q"},
+		{"[[links]]\npath = \"\\u12\"\n", "line 2: invalid unicode escape"},
+		{"[[links]]\nvisits = \"many\"\n", "line 2: unexpected value for visits"},
+		{"[[links]]\ncreated = 1979-13-27T07:32:00Z\n", "line 2: unsupported value"},
+		{"[[links]]\npath = \"a\" url = \"b\"\n", "line 2: unexpected"},
+		{"[site]\n", "line 1: only [[links]] tables are supported"},
+		{"a.b = \"c\"\n", "line 1: dotted keys are not supported"},
+		{"nyt = \"a\"\nnyt = \"b\"\n", "line 2: \"nyt\" is defined twice"},
+		{"nyt = 1\n", "line 1: the URL of \"nyt\" must be a string"},
+	} {
+		_, err := decodeTOML([]byte(tt.in))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("decodeTOML(%q) = %v, want %v", tt.in, err, tt.err)
+		}
+	}
+}
+
+// TestTOMLRoundTrip checks that exportTOML writes what decodeTOML reads back
+func TestTOMLRoundTrip(t *testing.T) {
+	recs := []record{
+		{Path: "nyt", URL: "https://www.nytimes.com"},
+		{
+			Path:        "q/{1}",
+			URL:         `https://example.com/search?q={1}&x="y"\z`,
+			Description: "Tabs\tnewlines\nquotes \" unicode é and a bell \a",
+			Tags:        []string{"search", "with, comma", "# not a comment"},
+			Creator:     "alice",
+			Visits:      42,
+			Created:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Updated:     time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC),
+			LastVisited: time.Date(2022, 11, 12, 13, 14, 15, 0, time.UTC),
+			PathMode:    "drop",
+			QueryMode:   "replace",
+			Fragment:    "top",
+			Params:      "utm_source=map",
+			Generated:   true,
+		},
+	}
+	var buf bytes.Buffer
+	if err := exportTOML(&buf, recs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeTOML(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decodeTOML of\n%s\n%v", buf.Bytes(), err)
+	}
+	if got := utc(got); !reflect.DeepEqual(got, recs) {
+		t.Errorf("round trip through\n%s\ngot  %+v\nwant %+v", buf.Bytes(), got, recs)
+	}
+}
+
+func TestExportTOML(t *testing.T) {
+	table, err := NewTable(persist.NewMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Save(persist.Short{Path: "nyt", Site: "https://www.nytimes.com", Tags: []string{"news"}}); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	SetHandler(table, Options{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export?format=toml", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /export?format=toml = %v %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/toml") {
+		t.Errorf("Content-Type = %v, want application/toml", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "links.toml") {
+		t.Errorf("Content-Disposition = %v, want links.toml", cd)
+	}
+	links, err := Decode(w.Body, FormatTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].Path != "nyt" || links[0].Site != "https://www.nytimes.com" || !reflect.DeepEqual(links[0].Tags, []string{"news"}) {
+		t.Errorf("exported %+v", links)
+	}
+}
@@ -0,0 +1,592 @@
+package urlshort
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"urlshort/persist"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Import and export formats
+const (
+	FormatJSON     = "json"      // list of records, also reads the flat form
+	FormatFlatJSON = "json-flat" // {"/path": "url"} as in ~/.map.json
+	FormatCSV      = "csv"
+	FormatYAML     = "yaml"
+	FormatTOML     = "toml"
+	FormatHTML     = "html" // Netscape bookmark file
+)
+
+// Formats lists every format accepted by Export and Decode
+var Formats = []string{FormatJSON, FormatFlatJSON, FormatCSV, FormatYAML, FormatTOML, FormatHTML}
+
+// FormatOf guesses the format of file from its extension
+func FormatOf(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return FormatCSV
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".html", ".htm":
+		return FormatHTML
+	}
+	return FormatJSON
+}
+
+// record is the interchange form of a link shared by the rich JSON, YAML
+// and CSV formats
+type record struct {
+	Path        string    `json:"path" yaml:"path"`
+	URL         string    `json:"url" yaml:"url"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Creator     string    `json:"creator,omitempty" yaml:"creator,omitempty"`
+	Visits      int       `json:"visits,omitempty" yaml:"visits,omitempty"`
+	Created     time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+	Updated     time.Time `json:"updated,omitempty" yaml:"updated,omitempty"`
+	LastVisited time.Time `json:"last_visited,omitempty" yaml:"last_visited,omitempty"`
+	PathMode    string    `json:"path_mode,omitempty" yaml:"path_mode,omitempty"`
+	QueryMode   string    `json:"query_mode,omitempty" yaml:"query_mode,omitempty"`
+	Fragment    string    `json:"fragment,omitempty" yaml:"fragment,omitempty"`
+	Params      string    `json:"params,omitempty" yaml:"params,omitempty"`
+	Generated   bool      `json:"generated,omitempty" yaml:"generated,omitempty"`
+}
+
+func toRecord(s persist.Short) record {
+	return record{
+		Path:        s.Path,
+		URL:         s.Site,
+		Description: s.Description,
+		Tags:        s.Tags,
+		Creator:     s.Creator,
+		Visits:      s.Count,
+		Created:     s.Created,
+		Updated:     s.Updated,
+		LastVisited: s.LastVisited,
+		PathMode:    s.Forward.Path,
+		QueryMode:   s.Forward.Query,
+		Fragment:    s.Forward.Fragment,
+		Params:      s.Forward.Params,
+		Generated:   s.Generated,
+	}
+}
+
+func (r record) short() persist.Short {
+	return persist.Short{
+		Path:        strings.TrimLeft(r.Path, "/"),
+		Site:        r.URL,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Creator:     r.Creator,
+		Count:       r.Visits,
+		Created:     r.Created,
+		Updated:     r.Updated,
+		LastVisited: r.LastVisited,
+		Forward: persist.Forward{
+			Path:     r.PathMode,
+			Query:    r.QueryMode,
+			Fragment: r.Fragment,
+			Params:   r.Params,
+		},
+		Generated: r.Generated,
+	}
+}
+
+var csvHeader = []string{"path", "url", "description", "tags", "creator", "visits", "created", "updated", "last_visited",
+	"path_mode", "query_mode", "fragment", "params", "generated"}
+
+// Export writes links to w in format, sorted by path
+func Export(w io.Writer, format string, links []persist.Short) error {
+	sort.Slice(links, func(i, j int) bool { return links[i].Path < links[j].Path })
+	recs := make([]record, 0, len(links))
+	for _, s := range links {
+		recs = append(recs, toRecord(s))
+	}
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+	case FormatFlatJSON:
+		flat := make(map[string]string, len(links))
+		for _, s := range links {
+			flat["/"+s.Path] = s.Site
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(flat)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(recs); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, r := range recs {
+			cw.Write([]string{r.Path, r.URL, r.Description, strings.Join(r.Tags, ";"), r.Creator,
+				strconv.Itoa(r.Visits), formatTime(r.Created), formatTime(r.Updated), formatTime(r.LastVisited),
+				r.PathMode, r.QueryMode, r.Fragment, r.Params, csvBool(r.Generated)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatTOML:
+		return exportTOML(w, recs)
+	case FormatHTML:
+		return exportHTML(w, recs)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Decode reads the links in r written in format. Both the flat and the
+// record forms are accepted for JSON, YAML and TOML, and syntax errors carry
+// the line they were found on.
+func Decode(r io.Reader, format string) ([]persist.Short, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var recs []record
+	switch format {
+	case FormatJSON, FormatFlatJSON:
+		recs, err = decodeJSON(b)
+	case FormatYAML:
+		recs, err = decodeYAML(b)
+	case FormatTOML:
+		recs, err = decodeTOML(b)
+	case FormatCSV:
+		recs, err = decodeCSV(b)
+	case FormatHTML:
+		recs, err = decodeHTML(b)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	links := make([]persist.Short, 0, len(recs))
+	for i, r := range recs {
+		s := r.short()
+		if s.Path == "" || s.Site == "" {
+			return nil, fmt.Errorf("link %v: path and url are required", i+1)
+		}
+		if err := s.Forward.Check(); err != nil {
+			return nil, fmt.Errorf("link %v: %v", s.Path, err)
+		}
+		if err := checkAbsolute(s.Site); err != nil {
+			return nil, fmt.Errorf("link %v: %v", s.Path, err)
+		}
+		if err := checkRule(s); err != nil {
+			return nil, err
+		}
+		links = append(links, s)
+	}
+	return links, nil
+}
+
+func decodeJSON(b []byte) ([]record, error) {
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '[' {
+		var recs []record
+		if err := json.Unmarshal(b, &recs); err != nil {
+			return nil, jsonLineError(b, err)
+		}
+		return recs, nil
+	}
+	flat := make(map[string]string)
+	if err := json.Unmarshal(b, &flat); err != nil {
+		return nil, jsonLineError(b, err)
+	}
+	return flatRecords(flat), nil
+}
+
+// jsonLineError adds the line the JSON decoder stopped at to err
+func jsonLineError(b []byte, err error) error {
+	var off int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		off = e.Offset
+	case *json.UnmarshalTypeError:
+		off = e.Offset
+	default:
+		return err
+	}
+	if off > int64(len(b)) {
+		off = int64(len(b))
+	}
+	return fmt.Errorf("line %d: %v", 1+bytes.Count(b[:off], []byte("\n")), err)
+}
+
+func decodeYAML(b []byte) ([]record, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	switch root := doc.Content[0]; root.Kind {
+	case yaml.SequenceNode:
+		var recs []record
+		if err := root.Decode(&recs); err != nil {
+			return nil, err
+		}
+		return recs, nil
+	case yaml.MappingNode:
+		flat := make(map[string]string)
+		if err := root.Decode(&flat); err != nil {
+			return nil, err
+		}
+		return flatRecords(flat), nil
+	default:
+		return nil, fmt.Errorf("line %d: expected a list of links or a mapping of paths to URLs", root.Line)
+	}
+}
+
+// flatRecords turns a path to URL map into records sorted by path
+func flatRecords(flat map[string]string) []record {
+	recs := make([]record, 0, len(flat))
+	for p, u := range flat {
+		recs = append(recs, record{Path: p, URL: u})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Path < recs[j].Path })
+	return recs
+}
+
+func decodeCSV(b []byte) ([]record, error) {
+	rows, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	col := make(map[string]int)
+	for i, h := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := col["path"]; !ok {
+		return nil, errors.New("csv header must name a path column")
+	}
+	if _, ok := col["url"]; !ok {
+		return nil, errors.New("csv header must name a url column")
+	}
+	var recs []record
+	for n, row := range rows[1:] {
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		r := record{
+			Path:        get("path"),
+			URL:         get("url"),
+			Description: get("description"),
+			Creator:     get("creator"),
+			PathMode:    get("path_mode"),
+			QueryMode:   get("query_mode"),
+			Fragment:    get("fragment"),
+			Params:      get("params"),
+		}
+		for _, t := range strings.Split(get("tags"), ";") {
+			if t = strings.TrimSpace(t); t != "" {
+				r.Tags = append(r.Tags, t)
+			}
+		}
+		if v := get("generated"); v != "" {
+			if r.Generated, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("line %v: generated: %v", n+2, err)
+			}
+		}
+		if v := get("visits"); v != "" {
+			if r.Visits, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("line %v: visits: %v", n+2, err)
+			}
+		}
+		for name, t := range map[string]*time.Time{"created": &r.Created, "updated": &r.Updated, "last_visited": &r.LastVisited} {
+			if *t, err = parseTime(get(name)); err != nil {
+				return nil, fmt.Errorf("line %v: %v: %v", n+2, name, err)
+			}
+		}
+		recs = append(recs, r)
+	}
+	return recs, nil
+}
+
+func csvBool(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+func exportHTML(w io.Writer, recs []record) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	b.WriteString("<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
+	b.WriteString("<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n<DL><p>\n")
+	for _, r := range recs {
+		fmt.Fprintf(&b, "    <DT><A HREF=\"%s\"", html.EscapeString(r.URL))
+		if !r.Created.IsZero() {
+			fmt.Fprintf(&b, " ADD_DATE=\"%d\"", r.Created.Unix())
+		}
+		if !r.Updated.IsZero() {
+			fmt.Fprintf(&b, " LAST_MODIFIED=\"%d\"", r.Updated.Unix())
+		}
+		fmt.Fprintf(&b, " SHORTCUTURL=\"%s\"", html.EscapeString(r.Path))
+		if len(r.Tags) > 0 {
+			fmt.Fprintf(&b, " TAGS=\"%s\"", html.EscapeString(strings.Join(r.Tags, ",")))
+		}
+		fmt.Fprintf(&b, ">%s</A>\n", html.EscapeString(r.Path))
+		if r.Description != "" {
+			fmt.Fprintf(&b, "    <DD>%s\n", html.EscapeString(r.Description))
+		}
+	}
+	b.WriteString("</DL><p>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var (
+	bookmarkRe = regexp.MustCompile(`(?is)<DT>\s*<A\s([^>]*)>(.*?)</A>(?:\s*<DD>([^<]*))?`)
+	attrRe     = regexp.MustCompile(`(?is)([A-Z_]+)\s*=\s*"([^"]*)"`)
+	slugRe     = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// decodeHTML reads the bookmarks of a Netscape bookmark file. Bookmarks
+// without a keyword (SHORTCUTURL) get a path made from their title.
+func decodeHTML(b []byte) ([]record, error) {
+	var recs []record
+	for _, m := range bookmarkRe.FindAllStringSubmatch(string(b), -1) {
+		attrs := make(map[string]string)
+		for _, a := range attrRe.FindAllStringSubmatch(m[1], -1) {
+			attrs[strings.ToUpper(a[1])] = html.UnescapeString(a[2])
+		}
+		title := strings.TrimSpace(html.UnescapeString(m[2]))
+		r := record{
+			Path:        attrs["SHORTCUTURL"],
+			URL:         attrs["HREF"],
+			Description: strings.TrimSpace(html.UnescapeString(m[3])),
+		}
+		if r.Path == "" {
+			r.Path = strings.Trim(slugRe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+		}
+		if r.Description == "" && title != r.Path {
+			r.Description = title
+		}
+		for _, t := range strings.Split(attrs["TAGS"], ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				r.Tags = append(r.Tags, t)
+			}
+		}
+		if v, err := strconv.ParseInt(attrs["ADD_DATE"], 10, 64); err == nil {
+			r.Created = time.Unix(v, 0)
+		}
+		if v, err := strconv.ParseInt(attrs["LAST_MODIFIED"], 10, 64); err == nil {
+			r.Updated = time.Unix(v, 0)
+		}
+		recs = append(recs, r)
+	}
+	return recs, nil
+}
+
+// Conflict policies for Import
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// ImportResult summarises what Import did, or would do on a dry run
+type ImportResult struct {
+	DryRun      bool
+	Added       []string
+	Overwritten []string
+	Skipped     []string
+	Renamed     map[string]string // imported path -> path it was saved under
+	Reserved    []string          // paths left out as built-in pages take them
+}
+
+func (r *ImportResult) String() string {
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Would import"
+	}
+	s := fmt.Sprintf("%v %v links: %v added, %v overwritten, %v renamed, %v skipped",
+		verb, len(r.Added)+len(r.Overwritten)+len(r.Renamed), len(r.Added), len(r.Overwritten), len(r.Renamed), len(r.Skipped))
+	if len(r.Reserved) > 0 {
+		s += fmt.Sprintf(", %v reserved", len(r.Reserved))
+	}
+	return s
+}
+
+// Import saves links into the table, resolving paths that already exist
+// with policy. Links under reserved paths are left out. Nothing is saved
+// on a dry run.
+func (t *Table) Import(links []persist.Short, policy string, dryRun bool) (*ImportResult, error) {
+	switch policy {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %q", policy)
+	}
+	res := &ImportResult{DryRun: dryRun, Renamed: make(map[string]string)}
+	taken := make(map[string]bool)
+	for k := range t.snapshot() {
+		taken[k] = true
+	}
+	var save []persist.Short
+	for _, s := range links {
+		if t.isReserved(s.Path) {
+			res.Reserved = append(res.Reserved, s.Path)
+			continue
+		}
+		if !taken[s.Path] {
+			res.Added = append(res.Added, s.Path)
+		} else {
+			switch policy {
+			case ConflictSkip:
+				res.Skipped = append(res.Skipped, s.Path)
+				continue
+			case ConflictOverwrite:
+				if old, _ := t.Lookup(s.Path); old.Generated && !s.Generated {
+					// The code may be handed out already
+					res.Skipped = append(res.Skipped, s.Path)
+					continue
+				}
+				res.Overwritten = append(res.Overwritten, s.Path)
+			case ConflictRename:
+				p := s.Path
+				for i := 2; taken[s.Path] || t.isReserved(s.Path); i++ {
+					s.Path = fmt.Sprintf("%v-%v", p, i)
+				}
+				res.Renamed[p] = s.Path
+			}
+		}
+		taken[s.Path] = true
+		save = append(save, s)
+	}
+	if dryRun {
+		return res, nil
+	}
+	return res, t.SaveAll(save)
+}
+
+// maxImportSize limits the size of files uploaded to /import
+const maxImportSize = 10 << 20
+
+var formatTypes = map[string]string{
+	FormatJSON:     "application/json",
+	FormatFlatJSON: "application/json",
+	FormatCSV:      "text/csv",
+	FormatYAML:     "application/x-yaml",
+	FormatTOML:     "application/toml",
+	FormatHTML:     "text/html",
+}
+
+var formatExts = map[string]string{
+	FormatJSON:     "json",
+	FormatFlatJSON: "json",
+	FormatCSV:      "csv",
+	FormatYAML:     "yaml",
+	FormatTOML:     "toml",
+	FormatHTML:     "html",
+}
+
+// exportHandler downloads every link in the format named by the format
+// query parameter
+func exportHandler(table *Table) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatJSON
+		}
+		ct, ok := formatTypes[format]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", ct+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"links.%v\"", formatExts[format]))
+		if err := Export(w, format, table.All()); err != nil {
+			log.Printf("Export failed: %v", err)
+		}
+	}
+}
+
+// importHandler imports the file uploaded from the list page and shows a
+// summary of the result. It is served behind the admin token.
+func importHandler(table *Table, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "no file uploaded: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		if err := checkCSRF(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		format := r.FormValue("format")
+		if format == "" {
+			format = FormatOf(hdr.Filename)
+		}
+		links, err := Decode(f, format)
+		if err != nil {
+			http.Error(w, "cannot read "+hdr.Filename+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		res, err := table.Import(links, r.FormValue("conflict"), r.FormValue("dryrun") != "")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		importTemplate, err := parseTemplate("import", opts)
+		if err != nil {
+			log.Println(err)
+			http.Error(w, "cannot show the page", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		render(w, r, importTemplate, "import", res)
+	}
+}